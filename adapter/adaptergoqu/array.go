@@ -0,0 +1,121 @@
+package adaptergoqu
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/rakunlabs/query"
+)
+
+// arrayExpr returns the array comparison of the field for the dialect.
+//   - postgres arrays: "tags" @> ARRAY[?,?], "tags" && ARRAY[?,?], "tags" <@ ARRAY[?,?]
+//   - postgres JSON arrays of WithJSONColumns: "tags" @> ?::jsonb, "tags" ?| array['a'], "tags" <@ ?::jsonb
+//   - mysql JSON arrays: JSON_CONTAINS(`tags`, ?), JSON_OVERLAPS(`tags`, ?), JSON_CONTAINS(?, `tags`)
+//
+// Items are bound as parameters with their type, numbers of query.WithKeyType are bound as numbers.
+func arrayExpr(e *query.ExpressionCmp, field fieldExpression, value any, opt *option) (exp.Expression, error) {
+	values := listItems(value)
+
+	switch opt.Dialect {
+	case "mysql":
+		data, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+
+		switch e.Operator {
+		case query.OperatorAOverlap:
+			return goqu.L("JSON_OVERLAPS(?, ?)", field, string(data)), nil
+		case query.OperatorAContainedBy:
+			return goqu.L("JSON_CONTAINS(?, ?)", string(data), field), nil
+		}
+
+		return goqu.L("JSON_CONTAINS(?, ?)", field, string(data)), nil
+	case "sqlite3":
+		return nil, fmt.Errorf("%w: operator [%s] for dialect [%s]", ErrNotSupported, e.Operator, opt.Dialect)
+	}
+
+	if _, ok := opt.JSONColumns[e.Field]; ok {
+		if e.Operator == query.OperatorAOverlap {
+			// JSONB has no overlap operator, ?| matches the string items.
+			strs := make([]string, len(values))
+			for i, v := range values {
+				strs[i] = fmt.Sprint(v)
+			}
+
+			return goqu.L("? ?| "+buildArrayLiteral(strs), field), nil
+		}
+
+		data, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Operator == query.OperatorAContainedBy {
+			return goqu.L("? <@ ?::jsonb", field, string(data)), nil
+		}
+
+		return goqu.L("? @> ?::jsonb", field, string(data)), nil
+	}
+
+	op := "@>"
+	switch e.Operator {
+	case query.OperatorAOverlap:
+		op = "&&"
+	case query.OperatorAContainedBy:
+		op = "<@"
+	}
+
+	if len(values) == 0 {
+		return goqu.L("? "+op+" '{}'", field), nil
+	}
+
+	args := make([]any, 0, len(values)+1)
+	args = append(args, field)
+	args = append(args, values...)
+
+	return goqu.L("? "+op+" ARRAY["+strings.TrimSuffix(strings.Repeat("?,", len(values)), ",")+"]", args...), nil
+}
+
+// arrayLengthExpr returns the length of an array field.
+//   - postgres: cardinality("tags"), jsonb_array_length("tags") for WithJSONColumns.
+//   - mysql: JSON_LENGTH(`tags`), sqlite3: json_array_length(`tags`)
+func arrayLengthExpr(field string, opt *option) fieldExpression {
+	column := fieldExpr(field, opt)
+
+	switch opt.Dialect {
+	case "mysql":
+		return goqu.L("JSON_LENGTH(?)", column)
+	case "sqlite3":
+		return goqu.L("json_array_length(?)", column)
+	}
+
+	if _, ok := opt.JSONColumns[field]; ok {
+		return goqu.L("jsonb_array_length(?)", column)
+	}
+
+	return goqu.L("cardinality(?)", column)
+}
+
+// listItems returns the items of a list value, a single value is a list of one item.
+func listItems(v any) []any {
+	if v == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []any{v}
+	}
+
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+
+	return items
+}
@@ -0,0 +1,102 @@
+package adaptergoqu
+
+import (
+	"slices"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/rakunlabs/query"
+)
+
+// Count returns a dataset counting the rows matching the query, to get the total of a paginated result.
+//   - The filter is the same as Select, ORDER BY, LIMIT, OFFSET and the selection are dropped.
+//   - Grouped queries are counted with a subquery, the result is the number of groups.
+func Count(q *query.Query, qq *goqu.SelectDataset, opts ...Option) *goqu.SelectDataset {
+	opt := &option{
+		Parameterized: true,
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	if opt.Dialect == "" {
+		opt.Dialect = qq.Dialect().Dialect()
+	}
+
+	if opt.Edit != nil {
+		q = opt.Edit(q)
+	}
+
+	qq = qq.ClearOrder().ClearLimit().ClearOffset()
+
+	if q != nil {
+		// Sort and selection are dropped, so they need no join.
+		if err := checkAggregates(&query.Query{Where: q.Where, Group: q.Group, Having: q.Having}, nil, opt); err != nil {
+			return qq.SetError(err)
+		}
+
+		fields, filters := queryFields(&query.Query{Where: q.Where, Group: q.Group, Having: q.Having}, nil)
+		if err := checkJSONPaths(slices.Concat(fields, filters), opt); err != nil {
+			return qq.SetError(err)
+		}
+
+		qq = joinRelations(qq, fields, filters, opt)
+
+		if len(q.Where) > 0 {
+			where, err := expressions(q.Where, opt)
+			if err != nil {
+				return qq.SetError(err)
+			}
+
+			qq = qq.Where(where...)
+		}
+
+		if len(q.Group) > 0 || len(q.Having) > 0 {
+			inner := qq.Select(goqu.L("1"))
+			if len(q.Group) > 0 {
+				group := make([]any, 0, len(q.Group))
+				for _, g := range q.Group {
+					group = append(group, fieldExpr(g, opt))
+				}
+
+				inner = inner.GroupBy(group...)
+			}
+
+			if len(q.Having) > 0 {
+				having, err := expressions(q.Having, opt)
+				if err != nil {
+					return qq.SetError(err)
+				}
+
+				inner = inner.Having(having...)
+			}
+
+			qq = goqu.Dialect(qq.Dialect().Dialect()).From(inner.As("count_query"))
+		}
+	}
+
+	qq = qq.Select(goqu.COUNT(goqu.Star()).As(goqu.C(opt.countAlias())))
+
+	if opt.Parameterized {
+		qq = qq.Prepared(true)
+	}
+
+	return qq
+}
+
+// SelectWithCount returns the datasets of Select and Count with the same options.
+func SelectWithCount(q *query.Query, qq *goqu.SelectDataset, opts ...Option) (*goqu.SelectDataset, *goqu.SelectDataset) {
+	return Select(q, qq, opts...), Count(q, qq, opts...)
+}
+
+// countOver returns the window count selected by WithCountOver.
+func countOver(opt *option) any {
+	return goqu.COUNT(goqu.Star()).Over(goqu.W()).As(goqu.C(opt.countAlias()))
+}
+
+func (o *option) countAlias() string {
+	if o.CountAlias != "" {
+		return o.CountAlias
+	}
+
+	return "count"
+}
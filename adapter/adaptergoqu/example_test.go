@@ -1,12 +1,17 @@
 package adaptergoqu_test
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
 	"testing"
 
 	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/mysql"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	_ "github.com/doug-martin/goqu/v9/dialect/sqlite3"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/rakunlabs/query"
 	"github.com/rakunlabs/query/adapter/adaptergoqu"
 )
@@ -408,3 +413,1178 @@ func TestCommaSplitOperatorSQL(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregateSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     []adaptergoqu.Option
+		wantSQL  string
+		parseErr bool
+	}{
+		{
+			name:    "group with aggregates",
+			query:   "_fields=status,count(*),sum(amount)&_group=status",
+			wantSQL: `SELECT "status", COUNT(*) AS "count", SUM("amount") AS "sum_amount" FROM "test" GROUP BY "status"`,
+		},
+		{
+			name:    "having and sort by aggregate",
+			query:   "_fields=country,avg(age)&_group=country&_having=count(*)[gt]=5|avg(age)[gte]=30&_sort=-count(*)&deleted[is]=",
+			wantSQL: `SELECT "country", AVG("age") AS "avg_age" FROM "test" WHERE ("deleted" IS NULL) GROUP BY "country" HAVING ((COUNT(*) > '5') OR (AVG("age") >= '30')) ORDER BY COUNT(*) DESC`,
+		},
+		{
+			name:    "having with multiple keys",
+			query:   "_group=status&_having=count(*)[gt]=5&_having=max(amount)[lt]=100",
+			wantSQL: `SELECT * FROM "test" GROUP BY "status" HAVING ((COUNT(*) > '5') AND (MAX("amount") < '100'))`,
+		},
+		{
+			name:  "rename and alias",
+			query: "_fields=min(age)&_group=country",
+			opts: []adaptergoqu.Option{
+				adaptergoqu.WithRename(map[string]string{"age": "u.age", "country": "u.country"}),
+				adaptergoqu.WithAggregateAlias(func(a query.Aggregate) string { return "" }),
+			},
+			wantSQL: `SELECT MIN("u"."age") FROM "test" GROUP BY "u"."country"`,
+		},
+		{
+			name:     "having on a plain field",
+			query:    "_group=status&_having=status=active",
+			parseErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, query.WithAggregation(true))
+			if (err != nil) != tt.parseErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.parseErr)
+			}
+			if tt.parseErr {
+				return
+			}
+
+			opts := append([]adaptergoqu.Option{adaptergoqu.WithParameterized(false), adaptergoqu.WithAggregation(true)}, tt.opts...)
+			sql, _, err := adaptergoqu.Select(q, goqu.From("test"), opts...).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestAggregationDisabledSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *query.Query
+		opts []adaptergoqu.Option
+	}{
+		{
+			name: "filter on aggregate",
+			q:    query.Where(query.F("count(*)").Gt(5)),
+			opts: []adaptergoqu.Option{adaptergoqu.WithAggregation(true)},
+		},
+		{
+			name: "aggregate selection",
+			q:    &query.Query{Select: []string{"status", "count(*)"}},
+		},
+		{
+			name: "sort by aggregate",
+			q:    query.New().OrderBy("-max(age)"),
+		},
+		{
+			name: "group by",
+			q:    &query.Query{Group: []string{"status"}},
+		},
+		{
+			name: "aggregate default selection",
+			q:    query.New(),
+			opts: []adaptergoqu.Option{adaptergoqu.WithDefaultSelect("sum(amount)")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := adaptergoqu.Select(tt.q, goqu.Dialect("postgres").From("test"), tt.opts...).ToSQL()
+			if !errors.Is(err, adaptergoqu.ErrFieldNotAllowed) {
+				t.Fatalf("ToSQL() error = %v, want ErrFieldNotAllowed", err)
+			}
+		})
+	}
+
+	having := &query.Query{Having: []query.Expression{query.F("count(*)").Gt(1)}}
+	if _, _, err := adaptergoqu.Count(having, goqu.From("test")).ToSQL(); !errors.Is(err, adaptergoqu.ErrFieldNotAllowed) {
+		t.Fatalf("Count() error = %v, want ErrFieldNotAllowed", err)
+	}
+
+	where := query.Where(query.F("max(age)").Gt(1))
+	if _, _, err := adaptergoqu.Delete(where, goqu.Delete("test"), adaptergoqu.WithAggregation(true)).ToSQL(); !errors.Is(err, adaptergoqu.ErrFieldNotAllowed) {
+		t.Fatalf("Delete() error = %v, want ErrFieldNotAllowed", err)
+	}
+}
+
+func TestExpression(t *testing.T) {
+	relation := adaptergoqu.WithRelation("author", adaptergoqu.Relation{
+		Table:  "users",
+		On:     goqu.I("author.id").Eq(goqu.I("posts.author_id")),
+		Fields: []string{"name"},
+	})
+
+	tests := []struct {
+		name    string
+		query   string
+		opts    []adaptergoqu.Option
+		wantSQL string
+		wantErr error
+	}{
+		{
+			name:    "filters",
+			query:   "name=foo&author.name=bob",
+			opts:    []adaptergoqu.Option{relation},
+			wantSQL: `SELECT * FROM "posts" WHERE (("name" = 'foo') AND ("author"."name" = 'bob'))`,
+		},
+		{
+			name:    "relation field not allowed",
+			query:   "name=foo&author.secret[gt]=0",
+			opts:    []adaptergoqu.Option{relation},
+			wantErr: adaptergoqu.ErrFieldNotAllowed,
+		},
+		{
+			name:    "json path key not allowed",
+			query:   "meta->a'b=1",
+			wantErr: adaptergoqu.ErrFieldNotAllowed,
+		},
+		{
+			name:    "conversion error",
+			query:   "name=foo&tags[acontains]=a",
+			opts:    []adaptergoqu.Option{adaptergoqu.WithDialect("sqlite3")},
+			wantErr: adaptergoqu.ErrNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			where, err := adaptergoqu.ExpressionErr(q, tt.opts...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ExpressionErr() error = %v, want %v", err, tt.wantErr)
+				}
+
+				if got := adaptergoqu.Expression(q, tt.opts...); got != nil {
+					t.Fatalf("Expression() = %v, want nil on error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ExpressionErr() error = %v", err)
+			}
+
+			sql, _, err := goqu.From("posts").Where(where...).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestCountSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		dataset *goqu.SelectDataset
+		opts    []adaptergoqu.Option
+		wantSQL string
+	}{
+		{
+			name:    "filter only",
+			query:   "name=foo,bar&age[gt]=18&_sort=-age&_limit=10&_offset=20&_fields=id,name",
+			dataset: goqu.From("test"),
+			wantSQL: `SELECT COUNT(*) AS "count" FROM "test" WHERE (("name" IN ('foo', 'bar')) AND ("age" > '18'))`,
+		},
+		{
+			name:    "no filter",
+			query:   "_limit=10",
+			dataset: goqu.From("test"),
+			wantSQL: `SELECT COUNT(*) AS "count" FROM "test"`,
+		},
+		{
+			name:    "dataset order and limit are dropped",
+			query:   "name=foo",
+			dataset: goqu.From("test").Order(goqu.I("id").Asc()).Limit(5),
+			opts:    []adaptergoqu.Option{adaptergoqu.WithCountAlias("total")},
+			wantSQL: `SELECT COUNT(*) AS "total" FROM "test" WHERE ("name" = 'foo')`,
+		},
+		{
+			name:    "grouped",
+			query:   "_fields=status,count(*)&_group=status&_having=count(*)[gt]=1&deleted[is]=",
+			dataset: goqu.From("test"),
+			opts:    []adaptergoqu.Option{adaptergoqu.WithAggregation(true)},
+			wantSQL: `SELECT COUNT(*) AS "count" FROM (SELECT 1 FROM "test" WHERE ("deleted" IS NULL) GROUP BY "status" HAVING (COUNT(*) > '1')) AS "count_query"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, query.WithAggregation(true))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			opts := append([]adaptergoqu.Option{adaptergoqu.WithParameterized(false)}, tt.opts...)
+			sql, _, err := adaptergoqu.Count(q, tt.dataset, opts...).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestSelectWithCount(t *testing.T) {
+	q, err := query.Parse("name=foo&_sort=-age&_limit=10&_fields=id,name")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	selectDS, countDS := adaptergoqu.SelectWithCount(q, goqu.From("test"), adaptergoqu.WithParameterized(false))
+
+	sql, _, err := selectDS.ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := `SELECT "id", "name" FROM "test" WHERE ("name" = 'foo') ORDER BY "age" DESC LIMIT 10`; sql != want {
+		t.Errorf("select SQL = %s, want %s", sql, want)
+	}
+
+	sql, _, err = countDS.ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := `SELECT COUNT(*) AS "count" FROM "test" WHERE ("name" = 'foo')`; sql != want {
+		t.Errorf("count SQL = %s, want %s", sql, want)
+	}
+
+	sql, _, err = adaptergoqu.Select(q, goqu.From("test"), adaptergoqu.WithParameterized(false), adaptergoqu.WithCountOver()).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := `SELECT "id", "name", COUNT(*) OVER () AS "count" FROM "test" WHERE ("name" = 'foo') ORDER BY "age" DESC LIMIT 10`; sql != want {
+		t.Errorf("count over SQL = %s, want %s", sql, want)
+	}
+
+	q.Select = nil
+	sql, _, err = adaptergoqu.Select(q, goqu.From("test"), adaptergoqu.WithParameterized(false), adaptergoqu.WithCountOver()).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := `SELECT *, COUNT(*) OVER () AS "count" FROM "test" WHERE ("name" = 'foo') ORDER BY "age" DESC LIMIT 10`; sql != want {
+		t.Errorf("count over without fields SQL = %s, want %s", sql, want)
+	}
+}
+
+func TestUpdateDeleteSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		update  *goqu.UpdateDataset
+		delete  *goqu.DeleteDataset
+		opts    []adaptergoqu.Option
+		wantSQL string
+		wantErr error
+	}{
+		{
+			name:    "update with filter",
+			query:   "status=active&age[lt]=18&_fields=id",
+			update:  goqu.Update("users"),
+			wantSQL: `UPDATE "users" SET "archived"=TRUE WHERE (("status" = 'active') AND ("age" < '18'))`,
+		},
+		{
+			name:    "delete with filter",
+			query:   "status=active,pending",
+			delete:  goqu.Delete("users"),
+			wantSQL: `DELETE FROM "users" WHERE ("status" IN ('active', 'pending'))`,
+		},
+		{
+			name:    "update without where",
+			query:   "_limit=10",
+			update:  goqu.Update("users"),
+			wantErr: adaptergoqu.ErrNoWhere,
+		},
+		{
+			name:    "delete without where",
+			query:   "",
+			delete:  goqu.Delete("users"),
+			wantErr: adaptergoqu.ErrNoWhere,
+		},
+		{
+			name:    "delete with dataset where",
+			query:   "",
+			delete:  goqu.Delete("users").Where(goqu.C("tenant").Eq("a")),
+			wantSQL: `DELETE FROM "users" WHERE ("tenant" = 'a')`,
+		},
+		{
+			name:    "delete without where allowed",
+			query:   "",
+			delete:  goqu.Delete("users"),
+			opts:    []adaptergoqu.Option{adaptergoqu.WithRequireWhere(false)},
+			wantSQL: `DELETE FROM "users"`,
+		},
+		{
+			name:    "mysql sort and limit",
+			query:   "status=old&_sort=-created_at&_limit=100",
+			delete:  goqu.Dialect("mysql").Delete("users"),
+			wantSQL: "DELETE FROM `users` WHERE (`status` = 'old') ORDER BY `created_at` DESC LIMIT 100",
+		},
+		{
+			name:    "mysql update sort and limit",
+			query:   "status=old&_sort=created_at&_limit=100",
+			update:  goqu.Dialect("mysql").Update("users"),
+			wantSQL: "UPDATE `users` SET `archived`=1 WHERE (`status` = 'old') ORDER BY `created_at` ASC LIMIT 100",
+		},
+		{
+			name:    "limit not supported",
+			query:   "status=old&_limit=100",
+			delete:  goqu.Dialect("postgres").Delete("users"),
+			wantErr: adaptergoqu.ErrNotSupported,
+		},
+		{
+			name:    "offset not supported",
+			query:   "status=old&_limit=100&_offset=100",
+			update:  goqu.Dialect("mysql").Update("users"),
+			wantErr: adaptergoqu.ErrNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			opts := append([]adaptergoqu.Option{adaptergoqu.WithParameterized(false)}, tt.opts...)
+
+			var sql string
+			if tt.update != nil {
+				sql, _, err = adaptergoqu.Update(q, tt.update, goqu.Record{"archived": true}, opts...).ToSQL()
+			} else {
+				sql, _, err = adaptergoqu.Delete(q, tt.delete, opts...).ToSQL()
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ToSQL() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestJSONPathSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		dataset *goqu.SelectDataset
+		opts    []adaptergoqu.Option
+		parse   []query.OptionQuery
+		wantSQL string
+		wantErr bool
+	}{
+		{
+			name:    "postgres single key",
+			query:   "meta->color=red",
+			dataset: goqu.Dialect("postgres").From("test"),
+			wantSQL: `SELECT * FROM "test" WHERE ("meta"->>'color' = 'red')`,
+		},
+		{
+			name:    "postgres nested path",
+			query:   "meta->address->city[ilike]=%25ist%25&_sort=meta->address->city",
+			dataset: goqu.Dialect("postgres").From("test"),
+			wantSQL: `SELECT * FROM "test" WHERE ("meta"#>>ARRAY['address','city'] ILIKE '%ist%') ORDER BY "meta"#>>ARRAY['address','city'] ASC`,
+		},
+		{
+			name:    "postgres number hint",
+			query:   "meta->size[gt]=10&_fields=id,meta->size",
+			dataset: goqu.Dialect("postgres").From("test"),
+			opts:    []adaptergoqu.Option{adaptergoqu.WithFieldType("meta->size", query.ValueTypeNumber)},
+			wantSQL: `SELECT "id", CAST("meta"->>'size' AS NUMERIC) AS "meta->size" FROM "test" WHERE (CAST("meta"->>'size' AS NUMERIC) > 10)`,
+		},
+		{
+			name:    "postgres boolean value",
+			query:   "meta->active=true",
+			dataset: goqu.Dialect("postgres").From("test"),
+			parse:   []query.OptionQuery{query.WithKeyType("meta->active", query.ValueTypeBoolean)},
+			wantSQL: `SELECT * FROM "test" WHERE (CAST("meta"->>'active' AS BOOLEAN) IS TRUE)`,
+		},
+		{
+			name:    "dotted with json columns and rename",
+			query:   "meta.color=red,blue&users.name=foo",
+			dataset: goqu.Dialect("postgres").From("test"),
+			opts: []adaptergoqu.Option{
+				adaptergoqu.WithJSONColumns("meta"),
+				adaptergoqu.WithRename(map[string]string{"meta": "t.metadata"}),
+			},
+			wantSQL: `SELECT * FROM "test" WHERE (("t"."metadata"->>'color' IN ('red', 'blue')) AND ("users"."name" = 'foo'))`,
+		},
+		{
+			name:    "mysql",
+			query:   "meta->address->city=Ankara&tags->0=a&meta->first name=b",
+			dataset: goqu.Dialect("mysql").From("test"),
+			wantSQL: "SELECT * FROM `test` WHERE ((JSON_UNQUOTE(JSON_EXTRACT(`meta`, '$.address.city')) = 'Ankara') AND (JSON_UNQUOTE(JSON_EXTRACT(`tags`, '$[0]')) = 'a') AND (JSON_UNQUOTE(JSON_EXTRACT(`meta`, '$.\"first name\"')) = 'b'))",
+		},
+		{
+			name:    "mysql number hint",
+			query:   "meta->size[gte]=1.5",
+			dataset: goqu.Dialect("mysql").From("test"),
+			opts:    []adaptergoqu.Option{adaptergoqu.WithFieldType("meta->size", query.ValueTypeNumber)},
+			wantSQL: "SELECT * FROM `test` WHERE (CAST(JSON_UNQUOTE(JSON_EXTRACT(`meta`, '$.size')) AS DECIMAL(65,30)) >= 1.5)",
+		},
+		{
+			name:    "grouped path",
+			query:   "_fields=meta->color,count(*)&_group=meta->color&_sort=meta->color",
+			dataset: goqu.Dialect("postgres").From("test"),
+			opts:    []adaptergoqu.Option{adaptergoqu.WithAggregation(true)},
+			parse:   []query.OptionQuery{query.WithAggregation(true)},
+			wantSQL: `SELECT "meta"->>'color' AS "meta->color", COUNT(*) AS "count" FROM "test" GROUP BY "meta"->>'color' ORDER BY "meta"->>'color' ASC`,
+		},
+		{
+			name:    "key with a quote is rejected",
+			query:   "meta->a'b=1",
+			dataset: goqu.Dialect("postgres").From("test"),
+			wantErr: true,
+		},
+		{
+			name:    "selected key with a quote is rejected",
+			query:   "_fields=meta->a')||pg_sleep(1)--",
+			dataset: goqu.Dialect("postgres").From("test"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, tt.parse...)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			opts := append([]adaptergoqu.Option{adaptergoqu.WithParameterized(false)}, tt.opts...)
+			sql, _, err := adaptergoqu.Select(q, tt.dataset, opts...).ToSQL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToSQL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, adaptergoqu.ErrFieldNotAllowed) {
+					t.Fatalf("ToSQL() error = %v, want ErrFieldNotAllowed", err)
+				}
+
+				return
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	// The keys are literals, so the grouped path is the same expression in every clause of a prepared statement.
+	q, err := query.Parse("_fields=meta->color,count(*)&_group=meta->color&meta->size=1", query.WithAggregation(true))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	sql, params, err := adaptergoqu.Select(q, goqu.Dialect("postgres").From("test"), adaptergoqu.WithAggregation(true)).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+
+	want := `SELECT "meta"->>'color' AS "meta->color", COUNT(*) AS "count" FROM "test" WHERE ("meta"->>'size' = $1) GROUP BY "meta"->>'color'`
+	if sql != want || len(params) != 1 {
+		t.Errorf("parameterized SQL = %s %v, want %s", sql, params, want)
+	}
+}
+
+func TestRelationSQL(t *testing.T) {
+	relations := []adaptergoqu.Option{
+		adaptergoqu.WithParameterized(false),
+		adaptergoqu.WithRelation("author", adaptergoqu.Relation{
+			Table:  "users",
+			On:     goqu.I("author.id").Eq(goqu.I("posts.author_id")),
+			Fields: []string{"name", "email"},
+		}),
+		adaptergoqu.WithRelation("category", adaptergoqu.Relation{
+			Table: "categories",
+			Alias: "c",
+			On:    goqu.I("c.id").Eq(goqu.I("posts.category_id")),
+			Type:  adaptergoqu.JoinInner,
+		}),
+		adaptergoqu.WithRelation("comments", adaptergoqu.Relation{
+			Table:  "comments",
+			On:     goqu.I("comments.post_id").Eq(goqu.I("posts.id")),
+			Exists: true,
+		}),
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		count   bool
+		wantSQL string
+		wantErr error
+	}{
+		{
+			name:    "no relation used",
+			query:   "title=foo",
+			wantSQL: `SELECT * FROM "posts" WHERE ("title" = 'foo')`,
+		},
+		{
+			name:    "join used by a filter",
+			query:   "author.name[ilike]=%25bob%25",
+			wantSQL: `SELECT * FROM "posts" LEFT JOIN "users" AS "author" ON ("author"."id" = "posts"."author_id") WHERE ("author"."name" ILIKE '%bob%')`,
+		},
+		{
+			name:    "joins in order of use",
+			query:   "_fields=id,category.name&_sort=author.name&title=foo",
+			wantSQL: `SELECT "id", "c"."name" FROM "posts" INNER JOIN "categories" AS "c" ON ("c"."id" = "posts"."category_id") LEFT JOIN "users" AS "author" ON ("author"."id" = "posts"."author_id") WHERE ("title" = 'foo') ORDER BY "author"."name" ASC`,
+		},
+		{
+			name:    "exists relation",
+			query:   "comments.body[ilike]=%25spam%25&title=foo",
+			wantSQL: `SELECT * FROM "posts" WHERE (EXISTS (SELECT 1 FROM "comments" WHERE (("comments"."post_id" = "posts"."id") AND ("comments"."body" ILIKE '%spam%'))) AND ("title" = 'foo'))`,
+		},
+		{
+			name:    "count joins only filters",
+			query:   "author.email=a@b.c&_sort=category.name",
+			count:   true,
+			wantSQL: `SELECT COUNT(*) AS "count" FROM "posts" LEFT JOIN "users" AS "author" ON ("author"."id" = "posts"."author_id") WHERE ("author"."email" = 'a@b.c')`,
+		},
+		{
+			name:    "relation field not allowed",
+			query:   "author.password=x",
+			wantErr: adaptergoqu.ErrFieldNotAllowed,
+		},
+		{
+			name:    "length of relation field not allowed",
+			query:   "len(author.secret)[gt]=0&author.name=bob",
+			wantErr: adaptergoqu.ErrFieldNotAllowed,
+		},
+		{
+			name:    "length of relation field joins",
+			query:   "len(category.tags)[gt]=0",
+			wantSQL: `SELECT * FROM "posts" INNER JOIN "categories" AS "c" ON ("c"."id" = "posts"."category_id") WHERE (cardinality("c"."tags") > 0)`,
+		},
+		{
+			name:    "length of exists relation field",
+			query:   "len(comments.tags)[gt]=1",
+			wantSQL: `SELECT * FROM "posts" WHERE EXISTS (SELECT 1 FROM "comments" WHERE (("comments"."post_id" = "posts"."id") AND (cardinality("comments"."tags") > 1)))`,
+		},
+		{
+			name:    "exists relation in fields",
+			query:   "_fields=comments.body",
+			wantErr: errors.New("field [comments.body] of relation [comments] is only usable in filters"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			ds := adaptergoqu.Select(q, goqu.From("posts"), relations...)
+			if tt.count {
+				ds = adaptergoqu.Count(q, goqu.From("posts"), relations...)
+			}
+
+			sql, _, err := ds.ToSQL()
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+					t.Fatalf("ToSQL() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	q, err := query.Parse("author.name=bob")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	sql, _, err := adaptergoqu.Delete(q, goqu.Delete("posts"), relations...).ToSQL()
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+
+	if want := `DELETE FROM "posts" WHERE EXISTS (SELECT 1 FROM "users" AS "author" WHERE (("author"."id" = "posts"."author_id") AND ("author"."name" = 'bob')))`; sql != want {
+		t.Errorf("delete SQL = %s, want %s", sql, want)
+	}
+}
+
+func TestVirtualSQL(t *testing.T) {
+	opts := []adaptergoqu.Option{
+		adaptergoqu.WithParameterized(true),
+		adaptergoqu.WithRename(map[string]string{"last_name": "surname"}),
+		adaptergoqu.WithVirtual("full_name", func(col func(string) exp.Expression) exp.Expression {
+			return goqu.L("? || ' ' || ?", col("first_name"), col("last_name"))
+		}),
+		adaptergoqu.WithVirtual("age", func(col func(string) exp.Expression) exp.Expression {
+			return goqu.L("date_part('year', age(?))", col("birth_date"))
+		}),
+		adaptergoqu.WithFieldType("age", query.ValueTypeNumber),
+		adaptergoqu.WithAggregation(true),
+		adaptergoqu.WithVirtual("email", func(col func(string) exp.Expression) exp.Expression {
+			return goqu.L("lower(?)", col("email"))
+		}),
+		adaptergoqu.WithVirtual("nick", func(col func(string) exp.Expression) exp.Expression {
+			return goqu.L("coalesce(?, ?)", col("nick"), col("display_name"))
+		}),
+		adaptergoqu.WithVirtual("display_name", func(col func(string) exp.Expression) exp.Expression {
+			return goqu.L("coalesce(?, ?)", col("display_name"), col("nick"))
+		}),
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "filter",
+			query:    "full_name[ilike]=%25bob%25",
+			wantSQL:  `SELECT * FROM "users" WHERE (("first_name" || ' ' || "surname") ILIKE $1)`,
+			wantArgs: []any{"%bob%"},
+		},
+		{
+			name:     "value is bound",
+			query:    "full_name=x' OR '1'='1",
+			wantSQL:  `SELECT * FROM "users" WHERE (("first_name" || ' ' || "surname") = $1)`,
+			wantArgs: []any{"x' OR '1'='1"},
+		},
+		{
+			name:     "number hint",
+			query:    "age[gte]=18",
+			wantSQL:  `SELECT * FROM "users" WHERE ((date_part('year', age("birth_date"))) >= $1)`,
+			wantArgs: []any{int64(18)},
+		},
+		{
+			name:     "number hint in list",
+			query:    "age=18,21",
+			wantSQL:  `SELECT * FROM "users" WHERE ((date_part('year', age("birth_date"))) IN ($1, $2))`,
+			wantArgs: []any{int64(18), int64(21)},
+		},
+		{
+			name:     "fields and sort",
+			query:    "_fields=id,full_name&_sort=-age",
+			wantSQL:  `SELECT "id", ("first_name" || ' ' || "surname") AS "full_name" FROM "users" ORDER BY (date_part('year', age("birth_date"))) DESC`,
+			wantArgs: []any{},
+		},
+		{
+			name:     "aggregate of virtual field",
+			query:    "_fields=avg(age)",
+			wantSQL:  `SELECT AVG((date_part('year', age("birth_date")))) AS "avg_age" FROM "users"`,
+			wantArgs: []any{},
+		},
+		{
+			name:     "self reference is the column",
+			query:    "email=a@b.c&_sort=email",
+			wantSQL:  `SELECT * FROM "users" WHERE ((lower("email")) = $1) ORDER BY (lower("email")) ASC`,
+			wantArgs: []any{"a@b.c"},
+		},
+		{
+			name:     "cycle is the column",
+			query:    "nick=bob",
+			wantSQL:  `SELECT * FROM "users" WHERE ((coalesce("nick", (coalesce("display_name", "nick")))) = $1)`,
+			wantArgs: []any{"bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, query.WithAggregation(true))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			sql, args, err := adaptergoqu.Select(q, goqu.Dialect("postgres").From("users"), opts...).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Fatalf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestTableSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		opts    []adaptergoqu.Option
+		wantSQL string
+	}{
+		{
+			name:    "default table",
+			query:   "_fields=id,title&title=foo&_sort=-created_at",
+			opts:    []adaptergoqu.Option{adaptergoqu.WithTable("p")},
+			wantSQL: `SELECT "p"."id", "p"."title" FROM "posts" AS "p" WHERE ("p"."title" = 'foo') ORDER BY "p"."created_at" DESC`,
+		},
+		{
+			name:    "schema table",
+			query:   "title=foo",
+			opts:    []adaptergoqu.Option{adaptergoqu.WithTable("public.posts")},
+			wantSQL: `SELECT * FROM "posts" AS "p" WHERE ("public"."posts"."title" = 'foo')`,
+		},
+		{
+			name:  "field table",
+			query: "_fields=id,name&name=bob&_sort=name",
+			opts: []adaptergoqu.Option{
+				adaptergoqu.WithTable("p"),
+				adaptergoqu.WithFieldTable(map[string]string{"name": "u"}),
+			},
+			wantSQL: `SELECT "p"."id", "u"."name" FROM "posts" AS "p" WHERE ("u"."name" = 'bob') ORDER BY "u"."name" ASC`,
+		},
+		{
+			name:  "rename and default select",
+			query: "author=bob",
+			opts: []adaptergoqu.Option{
+				adaptergoqu.WithTable("p"),
+				adaptergoqu.WithDefaultSelect("id", "author"),
+				adaptergoqu.WithRename(map[string]string{"author": "name"}),
+				adaptergoqu.WithFieldTable(map[string]string{"author": "u"}),
+			},
+			wantSQL: `SELECT "p"."id", "u"."name" FROM "posts" AS "p" WHERE ("u"."name" = 'bob')`,
+		},
+		{
+			name:  "rename to a qualified name",
+			query: "email=a@b.c",
+			opts: []adaptergoqu.Option{
+				adaptergoqu.WithTable("p"),
+				adaptergoqu.WithRename(map[string]string{"email": "u.email"}),
+			},
+			wantSQL: `SELECT * FROM "posts" AS "p" WHERE ("u"."email" = 'a@b.c')`,
+		},
+		{
+			name:  "unqualified field",
+			query: "_fields=status,count(*)&_group=status&_sort=-count",
+			opts: []adaptergoqu.Option{
+				adaptergoqu.WithTable("p"),
+				adaptergoqu.WithFieldTable(map[string]string{"count": ""}),
+				adaptergoqu.WithAggregation(true),
+			},
+			wantSQL: `SELECT "p"."status", COUNT(*) AS "count" FROM "posts" AS "p" GROUP BY "p"."status" ORDER BY "count" DESC`,
+		},
+		{
+			name:    "aggregate and json path",
+			query:   "_fields=sum(amount)&meta->color=red",
+			opts:    []adaptergoqu.Option{adaptergoqu.WithTable("p"), adaptergoqu.WithAggregation(true)},
+			wantSQL: `SELECT SUM("p"."amount") AS "sum_amount" FROM "posts" AS "p" WHERE ("p"."meta"->>'color' = 'red')`,
+		},
+		{
+			name:  "relation",
+			query: "author.name=bob&title=foo",
+			opts: []adaptergoqu.Option{
+				adaptergoqu.WithTable("p"),
+				adaptergoqu.WithRelation("author", adaptergoqu.Relation{
+					Table: "users",
+					On:    goqu.I("author.id").Eq(goqu.I("p.author_id")),
+				}),
+			},
+			wantSQL: `SELECT * FROM "posts" AS "p" LEFT JOIN "users" AS "author" ON ("author"."id" = "p"."author_id") WHERE (("author"."name" = 'bob') AND ("p"."title" = 'foo'))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, query.WithAggregation(true))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			opts := append([]adaptergoqu.Option{adaptergoqu.WithParameterized(false)}, tt.opts...)
+			sql, _, err := adaptergoqu.Select(q, goqu.Dialect("postgres").From(goqu.T("posts").As("p")), opts...).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Fatalf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestSearchSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		dialect  string
+		opts     []adaptergoqu.Option
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "postgres",
+			query:    "body[search]=foo bar",
+			dialect:  "postgres",
+			wantSQL:  `SELECT * FROM "posts" WHERE to_tsvector("body") @@ plainto_tsquery($1)`,
+			wantArgs: []any{"foo bar"},
+		},
+		{
+			name:     "postgres websearch with config",
+			query:    "_q=foo -bar",
+			dialect:  "postgres",
+			opts:     []adaptergoqu.Option{adaptergoqu.WithSearch(adaptergoqu.Search{Config: "english", Websearch: true})},
+			wantSQL:  `SELECT * FROM "posts" WHERE (to_tsvector($1::regconfig, "title") @@ websearch_to_tsquery($2::regconfig, $3) OR to_tsvector($4::regconfig, "body") @@ websearch_to_tsquery($5::regconfig, $6))`,
+			wantArgs: []any{"english", "english", "foo -bar", "english", "english", "foo -bar"},
+		},
+		{
+			name:     "mysql",
+			query:    "body[search]=foo bar",
+			dialect:  "mysql",
+			wantSQL:  "SELECT * FROM `posts` WHERE MATCH (`body`) AGAINST (? IN NATURAL LANGUAGE MODE)",
+			wantArgs: []any{"foo bar"},
+		},
+		{
+			name:     "like fallback",
+			query:    "body[search]=foo 10%25",
+			dialect:  "postgres",
+			opts:     []adaptergoqu.Option{adaptergoqu.WithSearch(adaptergoqu.Search{Like: true})},
+			wantSQL:  `SELECT * FROM "posts" WHERE (("body" ILIKE $1) AND ("body" ILIKE $2))`,
+			wantArgs: []any{"%foo%", `%10\%%`},
+		},
+		{
+			name:     "mysql like fallback",
+			query:    "body[search]=foo",
+			dialect:  "mysql",
+			opts:     []adaptergoqu.Option{adaptergoqu.WithSearch(adaptergoqu.Search{Like: true})},
+			wantSQL:  "SELECT * FROM `posts` WHERE (`body` LIKE ?)",
+			wantArgs: []any{"%foo%"},
+		},
+		{
+			name:     "sqlite3",
+			query:    "_q=a_b",
+			dialect:  "sqlite3",
+			wantSQL:  "SELECT * FROM `posts` WHERE (`title` LIKE ? ESCAPE '\\' OR `body` LIKE ? ESCAPE '\\')",
+			wantArgs: []any{`%a\_b%`, `%a\_b%`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, query.WithSearchFields("title", "body"))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			sql, args, err := adaptergoqu.Select(q, goqu.Dialect(tt.dialect).From("posts"), tt.opts...).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Fatalf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestArraySQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		dialect  string
+		parse    []query.OptionQuery
+		opts     []adaptergoqu.Option
+		wantSQL  string
+		wantArgs []any
+		wantErr  error
+	}{
+		{
+			name:     "postgres contains",
+			query:    "tags[acontains]=a,b",
+			dialect:  "postgres",
+			wantSQL:  `SELECT * FROM "posts" WHERE "tags" @> ARRAY[$1,$2]`,
+			wantArgs: []any{"a", "b"},
+		},
+		{
+			name:     "postgres overlap with number key type",
+			query:    "ids[aoverlap]=1,2",
+			dialect:  "postgres",
+			parse:    []query.OptionQuery{query.WithKeyType("ids", query.ValueTypeNumber)},
+			wantSQL:  `SELECT * FROM "posts" WHERE "ids" && ARRAY[$1,$2]`,
+			wantArgs: []any{int64(1), int64(2)},
+		},
+		{
+			name:     "postgres contained by",
+			query:    "tags[acontainedby]=a,b,c",
+			dialect:  "postgres",
+			wantSQL:  `SELECT * FROM "posts" WHERE "tags" <@ ARRAY[$1,$2,$3]`,
+			wantArgs: []any{"a", "b", "c"},
+		},
+		{
+			name:     "postgres JSON contains",
+			query:    "tags[acontains]=a,b",
+			dialect:  "postgres",
+			opts:     []adaptergoqu.Option{adaptergoqu.WithJSONColumns("tags")},
+			wantSQL:  `SELECT * FROM "posts" WHERE "tags" @> $1::jsonb`,
+			wantArgs: []any{`["a","b"]`},
+		},
+		{
+			name:     "postgres JSON overlap",
+			query:    "tags[aoverlap]=a,b",
+			dialect:  "postgres",
+			opts:     []adaptergoqu.Option{adaptergoqu.WithJSONColumns("tags")},
+			wantSQL:  `SELECT * FROM "posts" WHERE "tags" ?| array['a','b']`,
+			wantArgs: []any{},
+		},
+		{
+			name:     "has all",
+			query:    "roles[jall]=admin,editor",
+			dialect:  "postgres",
+			wantSQL:  `SELECT * FROM "posts" WHERE "roles" ?& array['admin','editor']`,
+			wantArgs: []any{},
+		},
+		{
+			name:     "mysql",
+			query:    "tags[acontains]=a&ids[aoverlap]=1,2&tags[acontainedby]=a,b",
+			dialect:  "mysql",
+			parse:    []query.OptionQuery{query.WithKeyType("ids", query.ValueTypeNumber)},
+			wantSQL:  "SELECT * FROM `posts` WHERE (JSON_CONTAINS(`tags`, ?) AND JSON_OVERLAPS(`ids`, ?) AND JSON_CONTAINS(?, `tags`))",
+			wantArgs: []any{`["a"]`, `[1,2]`, `["a","b"]`},
+		},
+		{
+			name:     "postgres length",
+			query:    "len(tags)[gte]=2&_sort=-len(tags)&_fields=id,len(tags)",
+			dialect:  "postgres",
+			wantSQL:  `SELECT "id", cardinality("tags") AS "len_tags" FROM "posts" WHERE (cardinality("tags") >= $1) ORDER BY cardinality("tags") DESC`,
+			wantArgs: []any{int64(2)},
+		},
+		{
+			name:     "postgres JSON length",
+			query:    "len(tags)=0",
+			dialect:  "postgres",
+			opts:     []adaptergoqu.Option{adaptergoqu.WithJSONColumns("tags")},
+			wantSQL:  `SELECT * FROM "posts" WHERE (jsonb_array_length("tags") = $1)`,
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "mysql length",
+			query:    "len(tags)[lt]=3",
+			dialect:  "mysql",
+			wantSQL:  "SELECT * FROM `posts` WHERE (JSON_LENGTH(`tags`) < ?)",
+			wantArgs: []any{int64(3)},
+		},
+		{
+			name:    "sqlite3 not supported",
+			query:   "tags[acontains]=a",
+			dialect: "sqlite3",
+			wantErr: adaptergoqu.ErrNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, tt.parse...)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			sql, args, err := adaptergoqu.Select(q, goqu.Dialect(tt.dialect).From("posts"), tt.opts...).ToSQL()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ToSQL() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Fatalf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestNullSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		dialect  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "null literal",
+			query:    "name=null&nick[ne]=null",
+			dialect:  "postgres",
+			wantSQL:  `SELECT * FROM "users" WHERE (("name" IS NULL) AND ("nick" IS NOT NULL))`,
+			wantArgs: []any{},
+		},
+		{
+			name:     "is true and false",
+			query:    "active[is]=true&deleted[not]=false&verified[is]=false&banned[not]=true",
+			dialect:  "postgres",
+			wantSQL:  `SELECT * FROM "users" WHERE (("active" IS TRUE) AND ("deleted" IS NOT FALSE) AND ("verified" IS FALSE) AND ("banned" IS NOT TRUE))`,
+			wantArgs: []any{},
+		},
+		{
+			name:     "postgres distinct",
+			query:    "name[isdistinct]=foo&nick[notdistinct]=null",
+			dialect:  "postgres",
+			wantSQL:  `SELECT * FROM "users" WHERE ("name" IS DISTINCT FROM $1 AND "nick" IS NOT DISTINCT FROM NULL)`,
+			wantArgs: []any{"foo"},
+		},
+		{
+			name:     "mysql distinct",
+			query:    "name[isdistinct]=foo&nick[notdistinct]=bar",
+			dialect:  "mysql",
+			wantSQL:  "SELECT * FROM `users` WHERE (NOT (`name` <=> ?) AND `nick` <=> ?)",
+			wantArgs: []any{"foo", "bar"},
+		},
+		{
+			name:     "sqlite3 distinct",
+			query:    "name[isdistinct]=null",
+			dialect:  "sqlite3",
+			wantSQL:  "SELECT * FROM `users` WHERE `name` IS NOT NULL",
+			wantArgs: []any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, query.WithNullValue("null"))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			sql, args, err := adaptergoqu.Select(q, goqu.Dialect(tt.dialect).From("users")).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Fatalf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     []query.OptionQuery
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "ieq",
+			query:    "email[ieq]=Bob@Example.com",
+			wantSQL:  `SELECT * FROM "users" WHERE (LOWER("email") = LOWER($1))`,
+			wantArgs: []any{"Bob@Example.com"},
+		},
+		{
+			name:     "ine",
+			query:    "username[ine]=Admin",
+			wantSQL:  `SELECT * FROM "users" WHERE (LOWER("username") != LOWER($1))`,
+			wantArgs: []any{"Admin"},
+		},
+		{
+			name:     "iin",
+			query:    "username[iin]=Bob,Alice",
+			wantSQL:  `SELECT * FROM "users" WHERE (LOWER("username") IN (LOWER($1), LOWER($2)))`,
+			wantArgs: []any{"Bob", "Alice"},
+		},
+		{
+			name:     "ieq comma split",
+			query:    "email[ieq]=A@b.c,D@e.f",
+			opts:     []query.OptionQuery{query.WithCommaSplit("email")},
+			wantSQL:  `SELECT * FROM "users" WHERE ((LOWER("email") = LOWER($1)) OR (LOWER("email") = LOWER($2)))`,
+			wantArgs: []any{"A@b.c", "D@e.f"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			sql, args, err := adaptergoqu.Select(q, goqu.Dialect("postgres").From("users")).ToSQL()
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Fatalf("SQL = %s, want %s", sql, tt.wantSQL)
+			}
+
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package adaptergoqu
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/doug-martin/goqu/v9"
@@ -9,7 +10,20 @@ import (
 	"github.com/rakunlabs/query"
 )
 
+// Expression returns the where clause of the query as goqu expressions combined with AND.
+//   - The filters are checked like Select, aggregates, JSON path keys and the Fields of relations.
+//   - On any error nothing is returned, so a filter is never dropped silently; use ExpressionErr to get the error.
 func Expression(q *query.Query, opts ...Option) []exp.Expression {
+	where, err := ExpressionErr(q, opts...)
+	if err != nil {
+		return nil
+	}
+
+	return where
+}
+
+// ExpressionErr is Expression returning the error of a filter that cannot be converted or is not allowed.
+func ExpressionErr(q *query.Query, opts ...Option) ([]exp.Expression, error) {
 	opt := &option{}
 	for _, o := range opts {
 		o(opt)
@@ -20,57 +34,81 @@ func Expression(q *query.Query, opts ...Option) []exp.Expression {
 	}
 
 	if q == nil {
-		return nil
+		return nil, nil
 	}
 
-	if len(q.Where) > 0 {
-		where := []exp.Expression{}
-		stack := [][]goqu.Expression{{}}
-		q.Walk(func(t query.Token) error {
-			currentStack := &stack[len(stack)-1]
-			switch t.Type {
-			case query.WalkCurrent:
-				if exprCmp, ok := t.Expression.(*query.ExpressionCmp); ok {
-					e, err := exprCmpToGoqu(exprCmp, opt.Rename)
-					if err != nil {
-						return err
-					}
-
-					*currentStack = append(*currentStack, e)
+	filter := &query.Query{Where: q.Where}
+	if err := checkAggregates(filter, nil, opt); err != nil {
+		return nil, err
+	}
+
+	_, filters := queryFields(filter, nil)
+	if err := checkJSONPaths(filters, opt); err != nil {
+		return nil, err
+	}
+
+	if err := checkRelationFields(filters, opt); err != nil {
+		return nil, err
+	}
+
+	where, err := expressions(q.Where, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return where, nil
+}
+
+// expressions converts a list of expressions combined with AND.
+func expressions(list []query.Expression, opt *option) ([]exp.Expression, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	where := []exp.Expression{}
+	stack := [][]goqu.Expression{{}}
+	err := (&query.Query{Where: list}).Walk(func(t query.Token) error {
+		currentStack := &stack[len(stack)-1]
+		switch t.Type {
+		case query.WalkCurrent:
+			if exprCmp, ok := t.Expression.(*query.ExpressionCmp); ok {
+				e, err := exprCmpToGoqu(exprCmp, opt)
+				if err != nil {
+					return err
 				}
-			case query.WalkStart:
-				// add new stack
-				stack = append(stack, []goqu.Expression{})
-			case query.WalkEnd:
-				if exprLogic, ok := t.Expression.(*query.ExpressionLogic); ok {
-					e, err := exprLogicToGoqu(exprLogic, *currentStack)
-					if err != nil {
-						return err
-					}
-
-					if len(stack) > 1 {
-						// pop stack
-						stack = stack[:len(stack)-1]
-						// add to parent stack
-						stack[len(stack)-1] = append(stack[len(stack)-1], e)
-					} else {
-						// add to where
-						where = append(where, e)
-					}
+
+				*currentStack = append(*currentStack, e)
+			}
+		case query.WalkStart:
+			// add new stack
+			stack = append(stack, []goqu.Expression{})
+		case query.WalkEnd:
+			if exprLogic, ok := t.Expression.(*query.ExpressionLogic); ok {
+				e, err := exprLogicToGoqu(exprLogic, *currentStack)
+				if err != nil {
+					return err
+				}
+
+				if len(stack) > 1 {
+					// pop stack
+					stack = stack[:len(stack)-1]
+					// add to parent stack
+					stack[len(stack)-1] = append(stack[len(stack)-1], e)
 				} else {
-					return fmt.Errorf("unexpected expression type: %T", t.Expression)
+					// add to where
+					where = append(where, e)
 				}
-			default:
-				return fmt.Errorf("unsupported walk type: %d", t.Type)
+			} else {
+				return fmt.Errorf("unexpected expression type: %T", t.Expression)
 			}
+		default:
+			return fmt.Errorf("unsupported walk type: %d", t.Type)
+		}
 
-			return nil
-		})
-
-		return where
-	}
+		return nil
+	})
 
-	return nil
+	return where, err
 }
 
 func Select(q *query.Query, qq *goqu.SelectDataset, opts ...Option) *goqu.SelectDataset {
@@ -81,6 +119,10 @@ func Select(q *query.Query, qq *goqu.SelectDataset, opts ...Option) *goqu.Select
 		o(opt)
 	}
 
+	if opt.Dialect == "" {
+		opt.Dialect = qq.Dialect().Dialect()
+	}
+
 	if opt.Edit != nil {
 		q = opt.Edit(q)
 	}
@@ -96,39 +138,59 @@ func Select(q *query.Query, qq *goqu.SelectDataset, opts ...Option) *goqu.Select
 		selects = opt.DefaultSelect
 	}
 
+	if err := checkAggregates(q, selects, opt); err != nil {
+		return qq.SetError(err)
+	}
+
+	fields, filters := queryFields(q, selects)
+	if err := checkJSONPaths(slices.Concat(fields, filters), opt); err != nil {
+		return qq.SetError(err)
+	}
+
+	qq = joinRelations(qq, fields, filters, opt)
+
 	if len(selects) > 0 {
 		selectsAny := make([]any, 0, len(selects))
 		for _, s := range selects {
-			if rename, ok := opt.Rename[s]; ok {
-				s = rename
-			}
-
-			selectsAny = append(selectsAny, goqu.I(s))
+			selectsAny = append(selectsAny, selectExpr(s, opt))
 		}
 
 		qq = qq.Select(selectsAny...)
 	}
 
+	if opt.CountOver {
+		qq = qq.SelectAppend(countOver(opt))
+	}
+
 	if len(q.Where) > 0 {
-		qq = qq.Where(Expression(q, opts...)...)
+		where, err := expressions(q.Where, opt)
+		if err != nil {
+			return qq.SetError(err)
+		}
+
+		qq = qq.Where(where...)
 	}
 
-	if len(q.Sort) > 0 {
-		order := make([]exp.OrderedExpression, 0, len(q.Sort))
-		for _, o := range q.Sort {
-			field := o.Field
-			if rename, ok := opt.Rename[field]; ok {
-				field = rename
-			}
+	if len(q.Group) > 0 {
+		group := make([]any, 0, len(q.Group))
+		for _, g := range q.Group {
+			group = append(group, fieldExpr(g, opt))
+		}
 
-			if o.Desc {
-				order = append(order, goqu.I(field).Desc())
-			} else {
-				order = append(order, goqu.I(field).Asc())
-			}
+		qq = qq.GroupBy(group...)
+	}
+
+	if len(q.Having) > 0 {
+		having, err := expressions(q.Having, opt)
+		if err != nil {
+			return qq.SetError(err)
 		}
 
-		qq = qq.Order(order...)
+		qq = qq.Having(having...)
+	}
+
+	if len(q.Sort) > 0 {
+		qq = qq.Order(order(q.Sort, opt)...)
 	}
 
 	if q.Offset != nil {
@@ -148,6 +210,22 @@ func Select(q *query.Query, qq *goqu.SelectDataset, opts ...Option) *goqu.Select
 	return qq
 }
 
+// order converts the sort fields to ORDER BY expressions.
+func order(sorts []query.ExpressionSort, opt *option) []exp.OrderedExpression {
+	result := make([]exp.OrderedExpression, 0, len(sorts))
+	for _, o := range sorts {
+		field := fieldExpr(o.Field, opt)
+
+		if o.Desc {
+			result = append(result, field.Desc())
+		} else {
+			result = append(result, field.Asc())
+		}
+	}
+
+	return result
+}
+
 func exprLogicToGoqu(e *query.ExpressionLogic, stack []goqu.Expression) (goqu.Expression, error) {
 	switch e.Operator {
 	case query.OperatorAnd:
@@ -159,20 +237,193 @@ func exprLogicToGoqu(e *query.ExpressionLogic, stack []goqu.Expression) (goqu.Ex
 	return nil, fmt.Errorf("unsupported operator: [%s]", e.Operator)
 }
 
-func exprCmpToGoqu(e *query.ExpressionCmp, rename map[string]string) (goqu.Expression, error) {
-	field := e.Field
-	if rename, ok := rename[field]; ok {
-		field = rename
+// fieldExpression is a column or a computed value like an aggregate, usable in every clause.
+type fieldExpression interface {
+	exp.Expression
+	exp.Aliaseable
+	exp.Comparable
+	exp.Inable
+	exp.Isable
+	exp.Likeable
+	exp.Orderable
+}
+
+// fieldExpr returns the expression of a field, aggregates like sum(amount) are rendered as SQL functions.
+func fieldExpr(field string, opt *option) fieldExpression {
+	if e, ok := virtualExpr(field, opt); ok {
+		return e
+	}
+
+	if a, ok := query.ParseAggregate(field); ok {
+		return aggregateExpr(a, opt)
+	}
+
+	if column, ok := query.ParseArrayLength(field); ok {
+		return arrayLengthExpr(column, opt)
 	}
 
-	fieldI := goqu.I(field)
+	if column, path, ok := jsonPath(field, opt); ok {
+		return jsonCast(jsonExpr(column, path, opt), opt.FieldType[field], opt)
+	}
+
+	if _, ok := opt.Rename[field]; ok {
+		return columnExpr(field, opt)
+	}
+
+	if r, name, column, ok := relationField(field, opt); ok {
+		return goqu.T(r.alias(name)).Col(column)
+	}
+
+	return columnExpr(field, opt)
+}
+
+// columnExpr returns the column of a field renamed with WithRename,
+// qualified with the table of WithFieldTable or WithTable.
+//   - Qualified names like users.name are kept as they are.
+func columnExpr(field string, opt *option) exp.IdentifierExpression {
+	column := field
+	if rename, ok := opt.Rename[field]; ok {
+		column = rename
+	}
+
+	if strings.Contains(column, ".") {
+		return goqu.I(column)
+	}
+
+	table, ok := opt.FieldTable[field]
+	if !ok {
+		table = opt.Table
+	}
+
+	if table == "" {
+		return goqu.I(column)
+	}
+
+	// Parsed as schema.table.column, so a table like public.users is quoted part by part.
+	return goqu.I(table + "." + column)
+}
+
+// selectExpr returns the expression of a selected field.
+//   - Aggregates get an alias, see WithAggregateAlias.
+//   - JSON paths and virtual fields are named with the field, meta->color AS "meta->color".
+//   - Array lengths are named like aggregates, len(tags) AS "len_tags".
+func selectExpr(field string, opt *option) exp.Expression {
+	if _, ok := opt.Virtual[field]; ok {
+		return fieldExpr(field, opt).As(goqu.C(field))
+	}
+
+	if _, _, ok := jsonPath(field, opt); ok {
+		// The field is the column name of the extracted value.
+		return fieldExpr(field, opt).As(goqu.C(field))
+	}
+
+	if column, ok := query.ParseArrayLength(field); ok {
+		return fieldExpr(field, opt).As(goqu.C("len_" + strings.ReplaceAll(column, ".", "_")))
+	}
+
+	a, ok := query.ParseAggregate(field)
+	if !ok {
+		return fieldExpr(field, opt)
+	}
+
+	alias := aggregateAlias
+	if opt.AggregateAlias != nil {
+		alias = opt.AggregateAlias
+	}
+
+	if name := alias(a); name != "" {
+		return aggregateExpr(a, opt).As(goqu.C(name))
+	}
+
+	return aggregateExpr(a, opt)
+}
+
+func aggregateExpr(a query.Aggregate, opt *option) exp.SQLFunctionExpression {
+	var arg any = goqu.Star()
+	if a.Field != "*" {
+		arg = fieldExpr(a.Field, opt)
+	}
+
+	switch a.Func {
+	case query.AggregateSum:
+		return goqu.SUM(arg)
+	case query.AggregateAvg:
+		return goqu.AVG(arg)
+	case query.AggregateMin:
+		return goqu.MIN(arg)
+	case query.AggregateMax:
+		return goqu.MAX(arg)
+	}
+
+	return goqu.COUNT(arg)
+}
+
+// checkAggregates returns ErrFieldNotAllowed for the aggregates the query may not use.
+//   - Filters on aggregates are always an error, aggregates are only usable in having.
+//   - Group by, having and aggregates in the selection or the sort need WithAggregation.
+func checkAggregates(q *query.Query, selects []string, opt *option) error {
+	err := (&query.Query{Where: q.Where}).Walk(func(t query.Token) error {
+		if cmp, ok := t.Expression.(*query.ExpressionCmp); ok && t.Type == query.WalkCurrent && query.IsAggregate(cmp.Field) {
+			return fmt.Errorf("%w: filter on aggregate [%s], use having", ErrFieldNotAllowed, cmp.Field)
+		}
+
+		return nil
+	})
+	if err != nil || opt.Aggregation {
+		return err
+	}
+
+	if len(q.Group) > 0 || len(q.Having) > 0 {
+		return fmt.Errorf("%w: group by and having need WithAggregation", ErrFieldNotAllowed)
+	}
+
+	for _, field := range selects {
+		if query.IsAggregate(field) {
+			return fmt.Errorf("%w: aggregate [%s] needs WithAggregation", ErrFieldNotAllowed, field)
+		}
+	}
+
+	for _, s := range q.Sort {
+		if query.IsAggregate(s.Field) {
+			return fmt.Errorf("%w: sort by aggregate [%s] needs WithAggregation", ErrFieldNotAllowed, s.Field)
+		}
+	}
+
+	return nil
+}
+
+// aggregateAlias is the default alias of a selected aggregate.
+//   - count(*) -> count, sum(amount) -> sum_amount
+func aggregateAlias(a query.Aggregate) string {
+	if a.Field == "*" {
+		return string(a.Func)
+	}
+
+	return string(a.Func) + "_" + strings.ReplaceAll(a.Field, ".", "_")
+}
+
+func exprCmpToGoqu(e *query.ExpressionCmp, opt *option) (goqu.Expression, error) {
+	expr, err := cmpToGoqu(e, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if r, name, _, ok := relationField(baseField(e.Field), opt); ok && (r.Exists || opt.relationExists) {
+		return existsExpr(r, name, expr, opt), nil
+	}
+
+	return expr, nil
+}
+
+func cmpToGoqu(e *query.ExpressionCmp, opt *option) (goqu.Expression, error) {
+	fieldI := cmpFieldExpr(e, opt)
 
 	// Handle comma-split []string values for operators that support it.
 	if values, ok := e.Value.([]string); ok && len(values) > 1 {
 		if fn, logicOp, supported := commaSplitGoquFn(e.Operator, fieldI); supported {
 			exprs := make([]goqu.Expression, len(values))
 			for i, v := range values {
-				exprs[i] = fn(v)
+				exprs[i] = fn(typedValue(e.Field, v, opt))
 			}
 
 			if logicOp == "and" {
@@ -183,19 +434,21 @@ func exprCmpToGoqu(e *query.ExpressionCmp, rename map[string]string) (goqu.Expre
 		}
 	}
 
+	value := typedValue(e.Field, e.Value, opt)
+
 	switch e.Operator {
 	case query.OperatorEq:
-		return fieldI.Eq(e.Value), nil
+		return fieldI.Eq(value), nil
 	case query.OperatorNe:
-		return fieldI.Neq(e.Value), nil
+		return fieldI.Neq(value), nil
 	case query.OperatorGt:
-		return fieldI.Gt(e.Value), nil
+		return fieldI.Gt(value), nil
 	case query.OperatorLt:
-		return fieldI.Lt(e.Value), nil
+		return fieldI.Lt(value), nil
 	case query.OperatorGte:
-		return fieldI.Gte(e.Value), nil
+		return fieldI.Gte(value), nil
 	case query.OperatorLte:
-		return fieldI.Lte(e.Value), nil
+		return fieldI.Lte(value), nil
 	case query.OperatorLike:
 		return fieldI.Like(e.Value), nil
 	case query.OperatorILike:
@@ -204,14 +457,45 @@ func exprCmpToGoqu(e *query.ExpressionCmp, rename map[string]string) (goqu.Expre
 		return fieldI.NotLike(e.Value), nil
 	case query.OperatorNILike:
 		return fieldI.NotILike(e.Value), nil
+	case query.OperatorIEq:
+		return lower(fieldI).Eq(lower(e.Value)), nil
+	case query.OperatorINe:
+		return lower(fieldI).Neq(lower(e.Value)), nil
+	case query.OperatorIIn:
+		items := listItems(e.Value)
+		for i, v := range items {
+			items[i] = lower(v)
+		}
+
+		return lower(fieldI).In(items...), nil
 	case query.OperatorIn:
-		return fieldI.In(e.Value), nil
+		return fieldI.In(value), nil
 	case query.OperatorNIn:
-		return fieldI.NotIn(e.Value), nil
+		return fieldI.NotIn(value), nil
 	case query.OperatorIs:
+		switch v := e.Value.(type) {
+		case bool:
+			if v {
+				return fieldI.IsTrue(), nil
+			}
+
+			return fieldI.IsFalse(), nil
+		}
+
 		return fieldI.IsNull(), nil
 	case query.OperatorIsNot:
+		switch v := e.Value.(type) {
+		case bool:
+			if v {
+				return fieldI.IsNotTrue(), nil
+			}
+
+			return fieldI.IsNotFalse(), nil
+		}
+
 		return fieldI.IsNotNull(), nil
+	case query.OperatorIsDistinct, query.OperatorNotDistinct:
+		return distinctExpr(e.Operator, fieldI, value, opt), nil
 	case query.OperatorKV:
 		// For JSONB containment (@>) operator
 		return goqu.L("? @> ?", fieldI, e.Value), nil
@@ -221,37 +505,86 @@ func exprCmpToGoqu(e *query.ExpressionCmp, rename map[string]string) (goqu.Expre
 	case query.OperatorNJIn:
 		// For negated JSONB array "has any" (NOT ?|) operator
 		return goqu.L("NOT (? ?| "+buildArrayLiteral(e.Value)+")", fieldI), nil
+	case query.OperatorJAll:
+		// For JSONB array "has all" (?&) operator
+		return goqu.L("? ?& "+buildArrayLiteral(e.Value), fieldI), nil
+	case query.OperatorAContains, query.OperatorAOverlap, query.OperatorAContainedBy:
+		return arrayExpr(e, fieldI, value, opt)
+	case query.OperatorSearch:
+		return searchExpr(fieldI, e.Value, opt), nil
 	}
 
 	return nil, fmt.Errorf("unsupported operator: [%s]", e.Operator)
 }
 
+// lower returns LOWER of a value for case insensitive comparisons.
+// Both sides are lowered by the database, so an index on LOWER(column) can be used.
+func lower(v any) exp.SQLFunctionExpression {
+	return goqu.Func("LOWER", v)
+}
+
+// distinctExpr returns the null safe comparison of the dialect, a nil value compares with NULL.
+//   - IS DISTINCT FROM and IS NOT DISTINCT FROM
+//   - mysql: NOT (a <=> b) and a <=> b
+//   - sqlite3: IS NOT and IS
+func distinctExpr(op query.OperatorCmpType, field fieldExpression, value any, opt *option) exp.Expression {
+	distinct := op == query.OperatorIsDistinct
+	if value == nil {
+		value = goqu.L("NULL")
+	}
+
+	switch opt.Dialect {
+	case "mysql":
+		if distinct {
+			return goqu.L("NOT (? <=> ?)", field, value)
+		}
+
+		return goqu.L("? <=> ?", field, value)
+	case "sqlite3":
+		if distinct {
+			return goqu.L("? IS NOT ?", field, value)
+		}
+
+		return goqu.L("? IS ?", field, value)
+	}
+
+	if distinct {
+		return goqu.L("? IS DISTINCT FROM ?", field, value)
+	}
+
+	return goqu.L("? IS NOT DISTINCT FROM ?", field, value)
+}
+
 // commaSplitGoquFn returns a function that creates a goqu expression for a single value,
 // the logic operator to combine multiple expressions ("or" or "and"),
 // and whether the operator supports comma splitting.
 // Negated operators (ne, nlike, nilike) use AND; positive operators use OR.
-func commaSplitGoquFn(op query.OperatorCmpType, fieldI exp.IdentifierExpression) (func(string) goqu.Expression, string, bool) {
+func commaSplitGoquFn(op query.OperatorCmpType, fieldI fieldExpression) (func(any) goqu.Expression, string, bool) {
 	switch op {
 	case query.OperatorEq:
-		return func(v string) goqu.Expression { return fieldI.Eq(v) }, "or", true
+		return func(v any) goqu.Expression { return fieldI.Eq(v) }, "or", true
 	case query.OperatorNe:
-		return func(v string) goqu.Expression { return fieldI.Neq(v) }, "and", true
+		return func(v any) goqu.Expression { return fieldI.Neq(v) }, "and", true
+	case query.OperatorIEq:
+		return func(v any) goqu.Expression { return lower(fieldI).Eq(lower(v)) }, "or", true
+	case query.OperatorINe:
+		return func(v any) goqu.Expression { return lower(fieldI).Neq(lower(v)) }, "and", true
 	case query.OperatorGt:
-		return func(v string) goqu.Expression { return fieldI.Gt(v) }, "or", true
+		return func(v any) goqu.Expression { return fieldI.Gt(v) }, "or", true
 	case query.OperatorLt:
-		return func(v string) goqu.Expression { return fieldI.Lt(v) }, "or", true
+		return func(v any) goqu.Expression { return fieldI.Lt(v) }, "or", true
 	case query.OperatorGte:
-		return func(v string) goqu.Expression { return fieldI.Gte(v) }, "or", true
+		return func(v any) goqu.Expression { return fieldI.Gte(v) }, "or", true
 	case query.OperatorLte:
-		return func(v string) goqu.Expression { return fieldI.Lte(v) }, "or", true
+		return func(v any) goqu.Expression { return fieldI.Lte(v) }, "or", true
 	case query.OperatorLike:
-		return func(v string) goqu.Expression { return fieldI.Like(v) }, "or", true
+		return func(v any) goqu.Expression { return fieldI.Like(v) }, "or", true
 	case query.OperatorILike:
-		return func(v string) goqu.Expression { return fieldI.ILike(v) }, "or", true
+		return func(v any) goqu.Expression { return fieldI.ILike(v) }, "or", true
 	case query.OperatorNLike:
-		return func(v string) goqu.Expression { return fieldI.NotLike(v) }, "and", true
+		return func(v any) goqu.Expression { return fieldI.NotLike(v) }, "and", true
 	case query.OperatorNILike:
-		return func(v string) goqu.Expression { return fieldI.NotILike(v) }, "and", true
+		return func(v any) goqu.Expression { return fieldI.NotILike(v) }, "and", true
 	default:
 		return nil, "", false
 	}
@@ -0,0 +1,182 @@
+package adaptergoqu
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/rakunlabs/query"
+)
+
+// jsonPath returns the column and the keys of a JSON path field.
+//   - meta->color is always a JSON path.
+//   - meta.color is a JSON path when meta is set with WithJSONColumns.
+//   - Paths with a key that is not a valid JSON key are not JSON paths, see checkJSONPaths.
+func jsonPath(field string, opt *option) (string, []string, bool) {
+	column, path, ok := splitJSONPath(field, opt)
+	if !ok || checkJSONKeys(path) != nil {
+		return "", nil, false
+	}
+
+	return column, path, true
+}
+
+// splitJSONPath returns the column and the keys of a JSON path field without checking the keys.
+func splitJSONPath(field string, opt *option) (string, []string, bool) {
+	if column, path, ok := query.ParseJSONPath(field); ok {
+		return column, path, true
+	}
+
+	if len(opt.JSONColumns) > 0 {
+		column, rest, ok := strings.Cut(field, ".")
+		if _, isJSON := opt.JSONColumns[column]; ok && isJSON && rest != "" {
+			path := strings.Split(rest, ".")
+			if !slices.Contains(path, "") {
+				return column, path, true
+			}
+		}
+	}
+
+	return "", nil, false
+}
+
+// checkJSONPaths returns ErrFieldNotAllowed for a JSON path field with an invalid key.
+//   - Keys are written into the SQL as literals, so they may only have letters, digits, space, '_' and '-'.
+func checkJSONPaths(fields []string, opt *option) error {
+	for _, field := range fields {
+		if _, path, ok := splitJSONPath(field, opt); ok {
+			if err := checkJSONKeys(path); err != nil {
+				return fmt.Errorf("%w: json path [%s]: %w", ErrFieldNotAllowed, field, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkJSONKeys checks the characters of the keys of a JSON path.
+func checkJSONKeys(path []string) error {
+	for _, key := range path {
+		for _, r := range key {
+			if r != '_' && r != '-' && r != ' ' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return fmt.Errorf("invalid character %q in key [%s]", r, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonExpr returns the text value at the path of a JSON column for the dialect.
+//   - postgres: "meta"->>'color', "meta"#>>ARRAY['address','city']
+//   - mysql: JSON_UNQUOTE(JSON_EXTRACT(`meta`, '$."color"'))
+//   - sqlite3: JSON_EXTRACT(`meta`, '$."color"')
+//
+// Keys are written as string literals, not parameters, so the same path renders the same SQL
+// in every clause, like the select and the group by. The keys are checked with checkJSONKeys.
+func jsonExpr(column string, path []string, opt *option) exp.LiteralExpression {
+	columnI := columnExpr(column, opt)
+
+	switch opt.Dialect {
+	case "mysql":
+		return goqu.L("JSON_UNQUOTE(JSON_EXTRACT(?, "+sqlString(mysqlJSONPath(path))+"))", columnI)
+	case "sqlite3":
+		return goqu.L("JSON_EXTRACT(?, "+sqlString(mysqlJSONPath(path))+")", columnI)
+	}
+
+	if len(path) == 1 && !isIndex(path[0]) {
+		return goqu.L("?->>"+sqlString(path[0]), columnI)
+	}
+
+	keys := make([]string, 0, len(path))
+	for _, key := range path {
+		keys = append(keys, sqlString(key))
+	}
+
+	return goqu.L("?#>>ARRAY["+strings.Join(keys, ",")+"]", columnI)
+}
+
+// sqlString returns the value as a SQL string literal with the single quotes doubled.
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// mysqlJSONPath returns the JSON path of the keys, $.address.city or $[0].
+//   - Keys that are not plain identifiers are quoted, $."first name", the keys are checked with checkJSONKeys.
+func mysqlJSONPath(path []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, key := range path {
+		if isIndex(key) {
+			b.WriteString("[" + key + "]")
+
+			continue
+		}
+
+		if isIdentifier(key) {
+			b.WriteString("." + key)
+
+			continue
+		}
+
+		b.WriteString(`."` + key + `"`)
+	}
+
+	return b.String()
+}
+
+func isIdentifier(key string) bool {
+	for i, r := range key {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return key != ""
+}
+
+func isIndex(key string) bool {
+	_, err := strconv.ParseUint(key, 10, 64)
+
+	return err == nil
+}
+
+// jsonCast casts the text value of a JSON path to the value type.
+//   - Booleans are casted only for postgres, other dialects compare the text value.
+func jsonCast(e exp.LiteralExpression, valueType query.ValueType, opt *option) fieldExpression {
+	switch valueType {
+	case query.ValueTypeNumber:
+		if opt.Dialect == "mysql" {
+			return goqu.Cast(e, "DECIMAL(65,30)")
+		}
+
+		return goqu.Cast(e, "NUMERIC")
+	case query.ValueTypeBoolean:
+		if opt.Dialect == "mysql" || opt.Dialect == "sqlite3" {
+			return e
+		}
+
+		return goqu.Cast(e, "BOOLEAN")
+	}
+
+	return e
+}
+
+// cmpFieldExpr returns the field expression of a comparison.
+//   - JSON paths without a WithFieldType hint are casted to boolean for boolean values, see query.WithKeyType.
+func cmpFieldExpr(e *query.ExpressionCmp, opt *option) fieldExpression {
+	if _, ok := opt.FieldType[e.Field]; !ok {
+		switch e.Value.(type) {
+		case bool, []bool:
+			if column, path, ok := jsonPath(e.Field, opt); ok {
+				return jsonCast(jsonExpr(column, path, opt), query.ValueTypeBoolean, opt)
+			}
+		}
+	}
+
+	return fieldExpr(e.Field, opt)
+}
@@ -0,0 +1,215 @@
+package adaptergoqu
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/rakunlabs/query"
+)
+
+var (
+	// ErrNoWhere is returned by Update and Delete when neither the query nor the dataset has a where clause.
+	ErrNoWhere = errors.New("no where clause")
+	// ErrNotSupported is returned by Update and Delete when the query needs a clause the dialect does not support.
+	ErrNotSupported = errors.New("not supported by the dialect")
+)
+
+// Update returns a dataset updating the rows matching the query with the record, see goqu.UpdateDataset.Set.
+//   - Sort and Limit are added as ORDER BY and LIMIT, an error is set when the dialect does not support them.
+//   - An offset sets an error, the selection and aggregations are ignored.
+//   - Without a where clause an ErrNoWhere error is set, disable it with WithRequireWhere(false).
+//
+// Errors are set on the dataset and returned by ToSQL and the executors.
+func Update(q *query.Query, ds *goqu.UpdateDataset, record any, opts ...Option) *goqu.UpdateDataset {
+	opt := newMutationOption(opts...)
+	if opt.Dialect == "" {
+		opt.Dialect = ds.Dialect().Dialect()
+	}
+
+	if opt.Edit != nil {
+		q = opt.Edit(q)
+	}
+
+	ds = ds.Set(record)
+
+	if err := checkMutationFields(q, opt); err != nil {
+		return ds.SetError(err)
+	}
+
+	if q != nil && len(q.Where) > 0 {
+		where, err := expressions(q.Where, opt)
+		if err != nil {
+			return ds.SetError(err)
+		}
+
+		ds = ds.Where(where...)
+	}
+
+	if opt.RequireWhere && isEmpty(ds.GetClauses().Where()) {
+		return ds.SetError(ErrNoWhere)
+	}
+
+	if q != nil {
+		if len(q.Sort) > 0 {
+			if !supportsUpdate(ds.Dialect().Dialect(), "ORDER BY") {
+				return ds.SetError(notSupported("ORDER BY on UPDATE", ds.Dialect().Dialect()))
+			}
+
+			ds = ds.Order(order(q.Sort, opt)...)
+		}
+
+		if q.Limit != nil && *q.Limit != 0 {
+			if !supportsUpdate(ds.Dialect().Dialect(), "LIMIT") {
+				return ds.SetError(notSupported("LIMIT on UPDATE", ds.Dialect().Dialect()))
+			}
+
+			ds = ds.Limit(uint(*q.Limit))
+		}
+
+		if q.GetOffset() > 0 {
+			return ds.SetError(notSupported("OFFSET on UPDATE", ds.Dialect().Dialect()))
+		}
+	}
+
+	if opt.Parameterized {
+		ds = ds.Prepared(true)
+	}
+
+	return ds
+}
+
+// Delete returns a dataset deleting the rows matching the query.
+//   - Sort and Limit are added as ORDER BY and LIMIT, an error is set when the dialect does not support them.
+//   - An offset sets an error, the selection and aggregations are ignored.
+//   - Without a where clause an ErrNoWhere error is set, disable it with WithRequireWhere(false).
+//
+// Errors are set on the dataset and returned by ToSQL and the executors.
+func Delete(q *query.Query, ds *goqu.DeleteDataset, opts ...Option) *goqu.DeleteDataset {
+	opt := newMutationOption(opts...)
+	if opt.Dialect == "" {
+		opt.Dialect = ds.Dialect().Dialect()
+	}
+
+	if opt.Edit != nil {
+		q = opt.Edit(q)
+	}
+
+	if err := checkMutationFields(q, opt); err != nil {
+		return ds.SetError(err)
+	}
+
+	if q != nil && len(q.Where) > 0 {
+		where, err := expressions(q.Where, opt)
+		if err != nil {
+			return ds.SetError(err)
+		}
+
+		ds = ds.Where(where...)
+	}
+
+	if opt.RequireWhere && isEmpty(ds.GetClauses().Where()) {
+		return ds.SetError(ErrNoWhere)
+	}
+
+	if q != nil {
+		if len(q.Sort) > 0 {
+			if !supportsDelete(ds.Dialect().Dialect(), "ORDER BY") {
+				return ds.SetError(notSupported("ORDER BY on DELETE", ds.Dialect().Dialect()))
+			}
+
+			ds = ds.Order(order(q.Sort, opt)...)
+		}
+
+		if q.Limit != nil && *q.Limit != 0 {
+			if !supportsDelete(ds.Dialect().Dialect(), "LIMIT") {
+				return ds.SetError(notSupported("LIMIT on DELETE", ds.Dialect().Dialect()))
+			}
+
+			ds = ds.Limit(uint(*q.Limit))
+		}
+
+		if q.GetOffset() > 0 {
+			return ds.SetError(notSupported("OFFSET on DELETE", ds.Dialect().Dialect()))
+		}
+	}
+
+	if opt.Parameterized {
+		ds = ds.Prepared(true)
+	}
+
+	return ds
+}
+
+func newMutationOption(opts ...Option) *option {
+	opt := &option{
+		Parameterized:  true,
+		RequireWhere:   true,
+		relationExists: true,
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return opt
+}
+
+func isEmpty(where exp.ExpressionList) bool {
+	return where == nil || where.IsEmpty()
+}
+
+func notSupported(clause, dialect string) error {
+	return fmt.Errorf("%w: %s with dialect [%s]", ErrNotSupported, clause, dialect)
+}
+
+// supportsUpdate reports whether the dialect renders the clause in UPDATE statements.
+// goqu skips unsupported clauses silently, which would widen the statement to every matching row.
+func supportsUpdate(dialect, clause string) bool {
+	sql, _, _ := goqu.Dialect(dialect).Update("t").Set(goqu.Record{"c": 1}).Order(goqu.I("c").Asc()).Limit(1).ToSQL()
+
+	return strings.Contains(sql, clause)
+}
+
+// supportsDelete reports whether the dialect renders the clause in DELETE statements.
+func supportsDelete(dialect, clause string) bool {
+	sql, _, _ := goqu.Dialect(dialect).Delete("t").Order(goqu.I("c").Asc()).Limit(1).ToSQL()
+
+	return strings.Contains(sql, clause)
+}
+
+// checkMutationFields checks the aggregates, the JSON paths and the relation fields of a statement without joins.
+//   - Relation filters are EXISTS subqueries, relation fields in the sort are not supported.
+func checkMutationFields(q *query.Query, opt *option) error {
+	if q == nil {
+		return nil
+	}
+
+	// Statements without grouping never use aggregates, whatever WithAggregation is.
+	if err := checkAggregates(&query.Query{Where: q.Where, Sort: q.Sort}, nil, &option{}); err != nil {
+		return err
+	}
+
+	fields, filters := queryFields(&query.Query{Where: q.Where, Sort: q.Sort}, nil)
+	if err := checkJSONPaths(slices.Concat(fields, filters), opt); err != nil {
+		return err
+	}
+
+	if len(opt.Relations) == 0 {
+		return nil
+	}
+
+	if err := checkRelationFields(filters, opt); err != nil {
+		return err
+	}
+
+	for _, s := range q.Sort {
+		if _, _, _, ok := relationField(baseField(s.Field), opt); ok {
+			return fmt.Errorf("%w: sort by relation field [%s]", ErrNotSupported, s.Field)
+		}
+	}
+
+	return nil
+}
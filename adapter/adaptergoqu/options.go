@@ -6,7 +6,31 @@ type option struct {
 	Edit          func(q *query.Query) *query.Query
 	Rename        map[string]string
 	DefaultSelect []string
+	Table         string
+	FieldTable    map[string]string
 	Parameterized bool
+
+	Aggregation    bool
+	AggregateAlias func(a query.Aggregate) string
+
+	CountOver  bool
+	CountAlias string
+
+	RequireWhere bool
+
+	Dialect     string
+	JSONColumns map[string]struct{}
+	FieldType   map[string]query.ValueType
+
+	Virtual map[string]VirtualFunc
+	// expanding are the virtual fields being expanded, col of one of them is the plain column.
+	expanding []string
+
+	Search Search
+
+	Relations map[string]*Relation
+	// relationExists renders every relation filter as EXISTS, statements without joins like UPDATE and DELETE.
+	relationExists bool
 }
 
 type Option func(*option)
@@ -29,6 +53,24 @@ func WithDefaultSelect(selects ...string) Option {
 	}
 }
 
+// WithTable qualifies the fields with a table or alias, name -> "users"."name".
+//   - Fields already qualified, renamed to a qualified name or of a relation are kept.
+//   - Use it with joins to avoid ambiguous columns.
+func WithTable(table string) Option {
+	return func(o *option) {
+		o.Table = table
+	}
+}
+
+// WithFieldTable sets the table or alias of fields, it takes precedence over WithTable.
+//   - An empty table keeps the field unqualified, like a selected alias.
+//   - The table is used in every clause, the selection, filters, group and sort.
+func WithFieldTable(fieldTable map[string]string) Option {
+	return func(o *option) {
+		o.FieldTable = fieldTable
+	}
+}
+
 // WithParameterized sets whether to use parameterized queries.
 //   - Default is true.
 func WithParameterized(parameterized bool) Option {
@@ -36,3 +78,124 @@ func WithParameterized(parameterized bool) Option {
 		o.Parameterized = parameterized
 	}
 }
+
+// WithAggregateAlias sets the alias of the selected aggregates, an empty alias selects without AS.
+//   - Default is the function and the field, count(*) -> count, sum(amount) -> sum_amount.
+func WithAggregateAlias(fn func(a query.Aggregate) string) Option {
+	return func(o *option) {
+		o.AggregateAlias = fn
+	}
+}
+
+// WithAggregation sets whether group by, having and aggregates like count(*) are rendered, like query.WithAggregation.
+//   - Default is false, Select and Count return ErrFieldNotAllowed for them.
+//   - Filters on aggregates are always an error, aggregates are only usable in having.
+func WithAggregation(v bool) Option {
+	return func(o *option) {
+		o.Aggregation = v
+	}
+}
+
+// WithCountOver adds the total count to the selection of Select with COUNT(*) OVER(),
+// so a page and its total are fetched in a single round trip.
+//   - The total ignores LIMIT and OFFSET, it is the number of groups for grouped queries.
+//   - The column is named with WithCountAlias, default count.
+func WithCountOver() Option {
+	return func(o *option) {
+		o.CountOver = true
+	}
+}
+
+// WithCountAlias sets the column name of the count of Count and WithCountOver.
+//   - Default is count.
+func WithCountAlias(alias string) Option {
+	return func(o *option) {
+		o.CountAlias = alias
+	}
+}
+
+// WithRequireWhere sets whether Update and Delete refuse a statement without a where clause.
+//   - Default is true, the where clause can come from the query or the dataset.
+func WithRequireWhere(v bool) Option {
+	return func(o *option) {
+		o.RequireWhere = v
+	}
+}
+
+// WithDialect sets the dialect used to render JSON paths in Expression.
+//   - Select, Count, Update and Delete use the dialect of the dataset by default.
+func WithDialect(dialect string) Option {
+	return func(o *option) {
+		o.Dialect = dialect
+	}
+}
+
+// WithJSONColumns sets the JSON columns, so dotted fields like meta.color are JSON paths.
+//   - Without it meta.color is the color column of the meta table, use meta->color instead.
+func WithJSONColumns(columns ...string) Option {
+	return func(o *option) {
+		if o.JSONColumns == nil {
+			o.JSONColumns = make(map[string]struct{}, len(columns))
+		}
+
+		for _, column := range columns {
+			o.JSONColumns[column] = struct{}{}
+		}
+	}
+}
+
+// WithFieldType sets the type of a JSON path field, the extracted text is casted to it.
+//   - query.ValueTypeNumber casts to NUMERIC, DECIMAL for mysql, so comparisons and sort are numeric.
+//   - query.ValueTypeBoolean casts to BOOLEAN for postgres.
+func WithFieldType(field string, valueType query.ValueType) Option {
+	return func(o *option) {
+		if o.FieldType == nil {
+			o.FieldType = make(map[string]query.ValueType)
+		}
+
+		o.FieldType[field] = valueType
+	}
+}
+
+// WithRelation adds a related table, fields prefixed with the name like author.name use it.
+// Only the relations used by a query are joined.
+//
+//	adaptergoqu.WithRelation("author", adaptergoqu.Relation{
+//	    Table: "users",
+//	    On:    goqu.I("author.id").Eq(goqu.I("posts.author_id")),
+//	})
+func WithRelation(name string, r Relation) Option {
+	return func(o *option) {
+		if o.Relations == nil {
+			o.Relations = make(map[string]*Relation)
+		}
+
+		o.Relations[name] = &r
+	}
+}
+
+// WithVirtual adds a virtual field computed by a SQL expression, usable in filters, _sort, _fields and _group.
+// Selected virtual fields are named with the name, AS "full_name".
+// Use WithFieldType as type hint, filter values are bound as numbers or booleans instead of strings.
+// Inside the function col of the field itself, or of a virtual field that is being expanded, is the plain column.
+//
+//	adaptergoqu.WithVirtual("full_name", func(col func(string) exp.Expression) exp.Expression {
+//	    return goqu.L("? || ' ' || ?", col("first_name"), col("last_name"))
+//	})
+func WithVirtual(name string, fn VirtualFunc) Option {
+	return func(o *option) {
+		if o.Virtual == nil {
+			o.Virtual = make(map[string]VirtualFunc)
+		}
+
+		o.Virtual[name] = fn
+	}
+}
+
+// WithSearch sets how the search operator and the global search are rendered, see Search.
+//   - Default is full text search for postgres and mysql, LIKE for other dialects.
+func WithSearch(s Search) Option {
+	return func(o *option) {
+		o.Search = s
+	}
+}
@@ -0,0 +1,225 @@
+package adaptergoqu
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/rakunlabs/query"
+)
+
+// ErrFieldNotAllowed is returned when a field of a relation is not in the Fields of the relation.
+var ErrFieldNotAllowed = errors.New("field is not allowed")
+
+type JoinType int
+
+const (
+	// JoinLeft adds the relation with LEFT JOIN, the default.
+	JoinLeft JoinType = iota
+	// JoinInner adds the relation with INNER JOIN.
+	JoinInner
+)
+
+// Relation is a related table usable with the name of the relation as field prefix, e.g. author.name.
+type Relation struct {
+	// Table is the related table.
+	Table string
+	// Alias is the alias of the table in the query, default is the relation name.
+	Alias string
+	// On is the join condition, use the alias for the related table.
+	//
+	//	goqu.I("author.id").Eq(goqu.I("posts.author_id"))
+	On exp.Expression
+	// Type is the join type, default JoinLeft.
+	Type JoinType
+	// Exists renders the filters as EXISTS subqueries instead of a join, for to-many relations.
+	//   - Every comparison is a separate EXISTS, matching any related row.
+	//   - The fields are only usable in filters.
+	Exists bool
+	// Fields are the allowed columns of the related table, empty allows every column.
+	Fields []string
+}
+
+// relationField returns the relation, its name and the column of a field like author.name.
+//   - Fields of WithRename are not relation fields.
+func relationField(field string, opt *option) (*Relation, string, string, bool) {
+	if len(opt.Relations) == 0 {
+		return nil, "", "", false
+	}
+
+	if _, ok := opt.Rename[field]; ok {
+		return nil, "", "", false
+	}
+
+	name, column, ok := strings.Cut(field, ".")
+	if !ok || column == "" {
+		return nil, "", "", false
+	}
+
+	r, ok := opt.Relations[name]
+	if !ok {
+		return nil, "", "", false
+	}
+
+	return r, name, column, true
+}
+
+func (r *Relation) alias(name string) string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+
+	return name
+}
+
+// table returns the table of the relation with its alias.
+func (r *Relation) table(name string) exp.Expression {
+	if alias := r.alias(name); alias != r.Table {
+		return goqu.T(r.Table).As(alias)
+	}
+
+	return goqu.T(r.Table)
+}
+
+// existsExpr wraps the comparison of a relation field in an EXISTS subquery.
+func existsExpr(r *Relation, name string, cmp exp.Expression, opt *option) exp.Expression {
+	sub := goqu.Dialect(opt.dialect()).From(r.table(name)).Select(goqu.L("1"))
+	if r.On != nil {
+		sub = sub.Where(r.On, cmp)
+	} else {
+		sub = sub.Where(cmp)
+	}
+
+	return goqu.L("EXISTS ?", sub)
+}
+
+func (o *option) dialect() string {
+	if o.Dialect != "" {
+		return o.Dialect
+	}
+
+	return "default"
+}
+
+// joinRelations checks the relation fields of the query and adds the joins they need, in the order of first use.
+//   - Filters of EXISTS relations need no join, other fields of EXISTS relations set an error.
+func joinRelations(qq *goqu.SelectDataset, fields, filters []string, opt *option) *goqu.SelectDataset {
+	if len(opt.Relations) == 0 {
+		return qq
+	}
+
+	if err := checkRelationFields(slices.Concat(fields, filters), opt); err != nil {
+		return qq.SetError(err)
+	}
+
+	var joined []string
+	join := func(field string, filter bool) error {
+		r, name, _, ok := relationField(field, opt)
+		if !ok || slices.Contains(joined, name) {
+			return nil
+		}
+
+		if r.Exists {
+			if filter {
+				return nil
+			}
+
+			return fmt.Errorf("field [%s] of relation [%s] is only usable in filters", field, name)
+		}
+
+		joined = append(joined, name)
+
+		table := r.table(name)
+		on := goqu.On()
+		if r.On != nil {
+			on = goqu.On(r.On)
+		}
+
+		switch r.Type {
+		case JoinInner:
+			qq = qq.InnerJoin(table, on)
+		default:
+			qq = qq.LeftJoin(table, on)
+		}
+
+		return nil
+	}
+
+	for _, field := range fields {
+		if err := join(field, false); err != nil {
+			return qq.SetError(err)
+		}
+	}
+
+	for _, field := range filters {
+		if err := join(field, true); err != nil {
+			return qq.SetError(err)
+		}
+	}
+
+	return qq
+}
+
+// checkRelationFields checks the columns of the relation fields against the Fields of the relations.
+func checkRelationFields(fields []string, opt *option) error {
+	for _, field := range fields {
+		r, name, column, ok := relationField(field, opt)
+		if !ok {
+			continue
+		}
+
+		if len(r.Fields) > 0 && !slices.Contains(r.Fields, column) {
+			return fmt.Errorf("%w: [%s.%s]", ErrFieldNotAllowed, name, column)
+		}
+	}
+
+	return nil
+}
+
+// baseField returns the field used by an aggregate or an array length, amount for sum(amount) and tags for len(tags).
+func baseField(field string) string {
+	if a, ok := query.ParseAggregate(field); ok {
+		field = a.Field
+	}
+
+	if column, ok := query.ParseArrayLength(field); ok {
+		return column
+	}
+
+	return field
+}
+
+// queryFields returns the fields used by the selection, group and sort, and the fields used by the filters.
+//   - Aggregates and array lengths are replaced by their argument, see baseField.
+func queryFields(q *query.Query, selects []string) ([]string, []string) {
+	field := baseField
+
+	fields := make([]string, 0, len(selects)+len(q.Group)+len(q.Sort))
+	for _, s := range selects {
+		fields = append(fields, field(s))
+	}
+
+	for _, g := range q.Group {
+		fields = append(fields, field(g))
+	}
+
+	for _, s := range q.Sort {
+		fields = append(fields, field(s.Field))
+	}
+
+	var filters []string
+	for _, list := range [][]query.Expression{q.Where, q.Having} {
+		(&query.Query{Where: list}).Walk(func(t query.Token) error {
+			if cmp, ok := t.Expression.(*query.ExpressionCmp); ok && t.Type == query.WalkCurrent {
+				filters = append(filters, field(cmp.Field))
+			}
+
+			return nil
+		})
+	}
+
+	return fields, filters
+}
@@ -0,0 +1,79 @@
+package adaptergoqu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+// Search sets how the search operator is rendered.
+//   - postgres: to_tsvector("body") @@ plainto_tsquery(?)
+//   - mysql: MATCH (`body`) AGAINST (? IN NATURAL LANGUAGE MODE), the column needs a FULLTEXT index.
+//   - other dialects: every word of the text with LIKE, "body" LIKE '%foo%' AND "body" LIKE '%bar%'
+type Search struct {
+	// Config is the PostgreSQL text search configuration like english, default is the database default.
+	Config string
+	// Websearch uses websearch_to_tsquery instead of plainto_tsquery, for "quoted phrases", or and -word.
+	Websearch bool
+	// Like uses the LIKE fallback in every dialect, for columns without a full text index.
+	Like bool
+}
+
+// searchExpr returns the search of the text in the field.
+// The text is bound as a parameter, LIKE wildcards in the text are escaped.
+func searchExpr(field fieldExpression, value any, opt *option) exp.Expression {
+	text, ok := value.(string)
+	if !ok {
+		text = fmt.Sprint(value)
+	}
+
+	if !opt.Search.Like {
+		switch opt.Dialect {
+		case "postgres":
+			fn := "plainto_tsquery"
+			if opt.Search.Websearch {
+				fn = "websearch_to_tsquery"
+			}
+
+			if opt.Search.Config != "" {
+				return goqu.L("to_tsvector(?::regconfig, ?) @@ "+fn+"(?::regconfig, ?)", opt.Search.Config, field, opt.Search.Config, text)
+			}
+
+			return goqu.L("to_tsvector(?) @@ "+fn+"(?)", field, text)
+		case "mysql":
+			return goqu.L("MATCH (?) AGAINST (? IN NATURAL LANGUAGE MODE)", field, text)
+		}
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	exprs := make([]exp.Expression, 0, len(words))
+	for _, word := range words {
+		pattern := "%" + escapeLike(word) + "%"
+		if opt.Dialect == "sqlite3" {
+			// SQLite has no default escape character.
+			exprs = append(exprs, goqu.L(`? LIKE ? ESCAPE '\'`, field, pattern))
+
+			continue
+		}
+
+		// ILIKE is rendered as the case insensitive LIKE of the dialect.
+		exprs = append(exprs, field.ILike(pattern))
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+
+	return goqu.And(exprs...)
+}
+
+// escapeLike escapes the LIKE wildcards with a backslash.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
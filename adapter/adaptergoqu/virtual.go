@@ -0,0 +1,83 @@
+package adaptergoqu
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/rakunlabs/query"
+)
+
+// VirtualFunc returns the SQL expression of a virtual field.
+//   - col returns the expression of another field with the rename, relation and JSON options applied.
+//   - Build the expression with goqu, like goqu.L("? || ' ' || ?", col("first_name"), col("last_name")),
+//     user values never reach the function, they are bound to the comparison as parameters.
+type VirtualFunc func(col func(field string) exp.Expression) exp.Expression
+
+// virtualExpr returns the expression of a virtual field, false when the field is not virtual.
+//   - A field that is being expanded is not virtual, so col("name") in the function of name is the column.
+func virtualExpr(field string, opt *option) (fieldExpression, bool) {
+	fn, ok := opt.Virtual[field]
+	if !ok || slices.Contains(opt.expanding, field) {
+		return nil, false
+	}
+
+	inner := *opt
+	inner.expanding = append(slices.Clone(opt.expanding), field)
+
+	e := fn(func(field string) exp.Expression {
+		return fieldExpr(field, &inner)
+	})
+
+	// Parentheses keep the expression together in comparisons, (a || b) = c.
+	return goqu.L("(?)", e), true
+}
+
+// typedValue converts the value to the type of WithFieldType, so it is bound with that type.
+//   - Numbers are converted to int64 or float64, booleans to bool.
+//   - Array lengths like len(tags) are numbers without a type hint.
+//   - Values that cannot be converted are kept as they are.
+func typedValue(field string, v any, opt *option) any {
+	valueType, ok := opt.FieldType[field]
+	if !ok {
+		if _, isLength := query.ParseArrayLength(field); !isLength {
+			return v
+		}
+
+		valueType = query.ValueTypeNumber
+	}
+
+	switch v := v.(type) {
+	case string:
+		return convertValue(v, valueType)
+	case []string:
+		result := make([]any, len(v))
+		for i, s := range v {
+			result[i] = convertValue(s, valueType)
+		}
+
+		return result
+	}
+
+	return v
+}
+
+func convertValue(s string, valueType query.ValueType) any {
+	switch valueType {
+	case query.ValueTypeNumber:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case query.ValueTypeBoolean:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+
+	return s
+}
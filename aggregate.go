@@ -0,0 +1,131 @@
+package query
+
+import "strings"
+
+type AggregateFunc string
+
+const (
+	AggregateCount AggregateFunc = "count"
+	AggregateSum   AggregateFunc = "sum"
+	AggregateAvg   AggregateFunc = "avg"
+	AggregateMin   AggregateFunc = "min"
+	AggregateMax   AggregateFunc = "max"
+)
+
+// Aggregate is an aggregate function call like count(*) or sum(amount).
+type Aggregate struct {
+	// Func is the aggregate function.
+	Func AggregateFunc
+	// Field is the argument of the function, "*" only for count.
+	Field string
+}
+
+// ParseAggregate parses an aggregate function call, false when s is not an aggregate.
+//   - count(*), count(id), sum(amount), avg(age), min(age), max(age)
+//   - Function names are case insensitive.
+func ParseAggregate(s string) (Aggregate, bool) {
+	open := strings.IndexByte(s, '(')
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return Aggregate{}, false
+	}
+
+	fn := AggregateFunc(strings.ToLower(s[:open]))
+	field := s[open+1 : len(s)-1]
+
+	switch fn {
+	case AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+	default:
+		return Aggregate{}, false
+	}
+
+	if field == "" || strings.ContainsAny(field, "(),") || (field == "*" && fn != AggregateCount) {
+		return Aggregate{}, false
+	}
+
+	return Aggregate{Func: fn, Field: field}, true
+}
+
+// String returns the aggregate in the query syntax, e.g. sum(amount).
+func (a Aggregate) String() string {
+	return string(a.Func) + "(" + a.Field + ")"
+}
+
+// IsAggregate reports whether the field is an aggregate function call.
+func IsAggregate(field string) bool {
+	_, ok := ParseAggregate(field)
+
+	return ok
+}
+
+// Aggregates returns the aggregates of the selection and the having clauses, in order and without duplicates.
+func (q *Query) Aggregates() []Aggregate {
+	var result []Aggregate
+	seen := make(map[Aggregate]struct{})
+
+	add := func(field string) {
+		a, ok := ParseAggregate(field)
+		if !ok {
+			return
+		}
+
+		if _, ok := seen[a]; ok {
+			return
+		}
+
+		seen[a] = struct{}{}
+		result = append(result, a)
+	}
+
+	for _, field := range q.Select {
+		add(field)
+	}
+
+	for _, expr := range q.Having {
+		walkCmp(expr, func(cmp *ExpressionCmp) {
+			add(cmp.Field)
+		})
+	}
+
+	return result
+}
+
+// selectFields returns the fields read by the selection and the having clauses.
+//   - Aggregates are replaced by their argument, count(*) reads no field.
+func (q *Query) selectFields() []string {
+	fields := make([]string, 0, len(q.Select))
+	add := func(field string) {
+		if a, ok := ParseAggregate(field); ok {
+			if a.Field != "*" {
+				fields = append(fields, a.Field)
+			}
+
+			return
+		}
+
+		fields = append(fields, field)
+	}
+
+	for _, field := range q.Select {
+		add(field)
+	}
+
+	for _, expr := range q.Having {
+		walkCmp(expr, func(cmp *ExpressionCmp) {
+			add(cmp.Field)
+		})
+	}
+
+	return fields
+}
+
+// walkCmp calls fn for every comparison of the expression tree.
+func walkCmp(expr Expression, fn func(*ExpressionCmp)) {
+	switch expr := expr.(type) {
+	case *ExpressionCmp:
+		fn(expr)
+	case *ExpressionLogic:
+		for _, e := range expr.List {
+			walkCmp(e, fn)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAggregate(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   Aggregate
+		wantOK bool
+	}{
+		{input: "count(*)", want: Aggregate{Func: AggregateCount, Field: "*"}, wantOK: true},
+		{input: "COUNT(id)", want: Aggregate{Func: AggregateCount, Field: "id"}, wantOK: true},
+		{input: "sum(amount)", want: Aggregate{Func: AggregateSum, Field: "amount"}, wantOK: true},
+		{input: "avg(age)", want: Aggregate{Func: AggregateAvg, Field: "age"}, wantOK: true},
+		{input: "sum(*)"},
+		{input: "sum()"},
+		{input: "sum(a,b)"},
+		{input: "lower(name)"},
+		{input: "name"},
+		{input: "(a=1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseAggregate(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("ParseAggregate() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseAggregation(t *testing.T) {
+	q, err := Parse("_fields=status,count(*),sum(amount)&_group=status,country&_having=count(*)[gt]=5|sum(amount)[gte]=100&_having=avg(age)[lt]=40&status=active", WithAggregation(true))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if want := []string{"status", "count(*)", "sum(amount)"}; !reflect.DeepEqual(q.Select, want) {
+		t.Fatalf("Select = %v, want %v", q.Select, want)
+	}
+
+	if want := []string{"status", "country"}; !reflect.DeepEqual(q.Group, want) {
+		t.Fatalf("Group = %v, want %v", q.Group, want)
+	}
+
+	wantHaving := []Expression{
+		NewExpressionLogic(OperatorOr, []Expression{
+			NewExpressionCmp(OperatorGt, "count(*)", "5"),
+			NewExpressionCmp(OperatorGte, "sum(amount)", "100"),
+		}),
+		NewExpressionCmp(OperatorLt, "avg(age)", "40"),
+	}
+	if !reflect.DeepEqual(q.Having, wantHaving) {
+		t.Fatalf("Having = %v, want %v", q.Having, wantHaving)
+	}
+
+	if want := []Expression{NewExpressionCmp(OperatorEq, "status", "active")}; !reflect.DeepEqual(q.Where, want) {
+		t.Fatalf("Where = %v, want %v", q.Where, want)
+	}
+
+	if q.Has("count(*)") {
+		t.Fatalf("Values contains the having comparisons")
+	}
+
+	wantAggregates := []Aggregate{
+		{Func: AggregateCount, Field: "*"},
+		{Func: AggregateSum, Field: "amount"},
+		{Func: AggregateAvg, Field: "age"},
+	}
+	if got := q.Aggregates(); !reflect.DeepEqual(got, wantAggregates) {
+		t.Fatalf("Aggregates() = %v, want %v", got, wantAggregates)
+	}
+
+	text, err := q.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+
+	back, err := Parse(string(text), WithAggregation(true))
+	if err != nil {
+		t.Fatalf("Parse(MarshalText()) error = %v", err)
+	}
+
+	if back.Fingerprint() != q.Fingerprint() {
+		t.Fatalf("MarshalText() = %s does not round trip", text)
+	}
+
+	if _, err := Parse("_having=name=foo", WithAggregation(true)); err == nil {
+		t.Fatalf("Parse() expected an error for a having field that is not an aggregate")
+	}
+}
+
+func TestParseAggregationDisabled(t *testing.T) {
+	q, err := Parse("_fields=status&_group=status&_having=count(*)[gt]=5&status=active")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(q.Group) != 0 || len(q.Having) != 0 {
+		t.Fatalf("Group = %v, Having = %v, want none without WithAggregation", q.Group, q.Having)
+	}
+
+	if want := []Expression{NewExpressionCmp(OperatorEq, "status", "active")}; !reflect.DeepEqual(q.Where, want) {
+		t.Fatalf("Where = %v, want %v", q.Where, want)
+	}
+
+	q, err = Parse("group=status&having=x&status=active", WithUnderscorePrefix(false))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(q.Group) != 0 || len(q.Having) != 0 || len(q.Where) != 3 {
+		t.Fatalf("Group = %v, Having = %v, Where = %v, want group and having as filters", q.Group, q.Having, q.Where)
+	}
+
+	q, err = ParseJSON([]byte(`{"group": ["status"], "having": [{"field": "count(*)", "op": "gt", "value": 5}]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	if len(q.Group) != 0 || len(q.Having) != 0 {
+		t.Fatalf("ParseJSON() Group = %v, Having = %v, want none without WithAggregation", q.Group, q.Having)
+	}
+	for _, value := range []string{"_fields=status,count(*)", "_sort=-max(age)"} {
+		if _, err := Parse(value); err == nil {
+			t.Fatalf("Parse(%s) expected an error for an aggregate without WithAggregation", value)
+		}
+	}
+
+	if _, err := ParseJSON([]byte(`{"fields": ["sum(amount)"]}`)); err == nil {
+		t.Fatalf("ParseJSON() expected an error for an aggregate without WithAggregation")
+	}
+
+	for _, value := range []string{"count(*)[gt]=5", "(name=a|max(age)[lt]=3)"} {
+		if _, err := Parse(value, WithAggregation(true)); err == nil {
+			t.Fatalf("Parse(%s) expected an error for a filter on an aggregate", value)
+		}
+	}
+}
+
+func TestValidateAggregation(t *testing.T) {
+	validator, err := NewValidator(
+		WithField(WithIn("status", "amount")),
+		WithGroup(WithIn("status")),
+		WithAggregate(WithIn("count(*)", "sum(amount)")),
+	)
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "allowed", query: "_fields=status,count(*),sum(amount)&_group=status&_having=count(*)[gt]=1"},
+		{name: "group not allowed", query: "_fields=count(*)&_group=country", wantErr: true},
+		{name: "aggregate not allowed", query: "_fields=max(amount)&_group=status", wantErr: true},
+		{name: "having aggregate not allowed", query: "_group=status&_having=avg(amount)[gt]=1", wantErr: true},
+		{name: "aggregate field not allowed", query: "_fields=sum(secret)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query, WithAggregation(true))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if err := q.Validate(validator); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package query
+
+import "strings"
+
+// ParseArrayLength returns the array field of a length field, false when the field is not a length.
+//   - len(tags) -> tags, usable in filters and _sort like len(tags)[gt]=2.
+func ParseArrayLength(field string) (string, bool) {
+	rest, ok := strings.CutPrefix(field, "len(")
+	if !ok {
+		return "", false
+	}
+
+	column, ok := strings.CutSuffix(rest, ")")
+	if !ok || column == "" || strings.ContainsAny(column, "()") {
+		return "", false
+	}
+
+	return column, true
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArrayLength(t *testing.T) {
+	tests := []struct {
+		field      string
+		wantColumn string
+		wantOK     bool
+	}{
+		{field: "len(tags)", wantColumn: "tags", wantOK: true},
+		{field: "len(meta->tags)", wantColumn: "meta->tags", wantOK: true},
+		{field: "tags"},
+		{field: "len()"},
+		{field: "len(tags"},
+		{field: "count(tags)"},
+		{field: "len(len(tags))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			column, ok := ParseArrayLength(tt.field)
+			if ok != tt.wantOK || column != tt.wantColumn {
+				t.Fatalf("ParseArrayLength() = %q, %v, want %q, %v", column, ok, tt.wantColumn, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseArrayOperators(t *testing.T) {
+	q, err := Parse("tags[acontains]=a,b&ids[aoverlap]=1,2.5&flags[acontainedby]=true&roles[jall]=admin,editor&len(tags)[gte]=2",
+		WithKeyType("ids", ValueTypeNumber),
+		WithKeyType("flags", ValueTypeBoolean),
+	)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Expression{
+		NewExpressionCmp(OperatorAContains, "tags", []string{"a", "b"}),
+		NewExpressionCmp(OperatorAOverlap, "ids", []any{int64(1), 2.5}),
+		NewExpressionCmp(OperatorAContainedBy, "flags", []bool{true}),
+		NewExpressionCmp(OperatorJAll, "roles", []string{"admin", "editor"}),
+		NewExpressionCmp(OperatorGte, "len(tags)", "2"),
+	}
+	if !reflect.DeepEqual(q.Where, want) {
+		t.Fatalf("Parse() Where = %v, want %v", q.Where, want)
+	}
+
+	text, err := q.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+
+	wantText := "tags[acontains]=a,b&ids[aoverlap]=1,2.5&flags[acontainedby]=true&roles[jall]=admin,editor&len(tags)[gte]=2"
+	if string(text) != wantText {
+		t.Fatalf("MarshalText() = %s, want %s", text, wantText)
+	}
+
+	q2, err := Parse(string(text), WithKeyType("ids", ValueTypeNumber), WithKeyType("flags", ValueTypeBoolean))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(q2.Where, want) {
+		t.Fatalf("Parse() of MarshalText Where = %v, want %v", q2.Where, want)
+	}
+
+	if _, err := Parse("ids[acontains]=1,x", WithKeyType("ids", ValueTypeNumber)); err == nil {
+		t.Fatalf("Parse() expected an error for an array item that is not a number")
+	}
+}
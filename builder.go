@@ -0,0 +1,185 @@
+package query
+
+// Field is a field name used to build comparisons.
+//
+//	q := query.Where(
+//	    query.F("age").Gt(18),
+//	    query.Or(query.F("name").ILike("%a%"), query.F("nick").Eq("b")),
+//	).OrderBy("-age").SetLimit(10)
+type Field string
+
+// F returns a Field to build comparisons.
+func F(field string) Field {
+	return Field(field)
+}
+
+// Where returns a new query with the expressions combined with AND.
+func Where(exprs ...Expression) *Query {
+	return New().AddWhere(exprs...)
+}
+
+// Or returns an OR group of the expressions.
+func Or(exprs ...Expression) *ExpressionLogic {
+	return NewExpressionLogic(OperatorOr, exprs)
+}
+
+// All returns an AND group of the expressions.
+//   - Use And to merge whole queries.
+func All(exprs ...Expression) *ExpressionLogic {
+	return NewExpressionLogic(OperatorAnd, exprs)
+}
+
+// OrderBy adds sort fields with the same syntax as the sort parameter.
+//   - "-age", "age:desc" for descending; "age", "+age", "age:asc" for ascending.
+func (q *Query) OrderBy(fields ...string) *Query {
+	for _, field := range fields {
+		q.Sort = append(q.Sort, parseSort(field)...)
+	}
+
+	return q
+}
+
+func (f Field) Eq(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorEq, string(f), value)
+}
+
+func (f Field) Ne(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorNe, string(f), value)
+}
+
+func (f Field) Gt(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorGt, string(f), value)
+}
+
+func (f Field) Lt(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorLt, string(f), value)
+}
+
+func (f Field) Gte(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorGte, string(f), value)
+}
+
+func (f Field) Lte(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorLte, string(f), value)
+}
+
+func (f Field) Like(pattern string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorLike, string(f), pattern)
+}
+
+func (f Field) ILike(pattern string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorILike, string(f), pattern)
+}
+
+func (f Field) NLike(pattern string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorNLike, string(f), pattern)
+}
+
+func (f Field) NILike(pattern string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorNILike, string(f), pattern)
+}
+
+// IEq returns a case insensitive equality comparison.
+func (f Field) IEq(value string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorIEq, string(f), value)
+}
+
+// INe returns a case insensitive not equal comparison.
+func (f Field) INe(value string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorINe, string(f), value)
+}
+
+// IIn returns a case insensitive IN comparison.
+func (f Field) IIn(values ...string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorIIn, string(f), values)
+}
+
+// In returns an IN comparison, string values are stored as []string like the parser does.
+func (f Field) In(values ...any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorIn, string(f), listValue(values))
+}
+
+// NIn returns a NOT IN comparison, string values are stored as []string like the parser does.
+func (f Field) NIn(values ...any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorNIn, string(f), listValue(values))
+}
+
+func (f Field) IsNull() *ExpressionCmp {
+	return NewExpressionCmp(OperatorIs, string(f), nil)
+}
+
+func (f Field) IsNotNull() *ExpressionCmp {
+	return NewExpressionCmp(OperatorIsNot, string(f), nil)
+}
+
+// Is returns an IS TRUE or IS FALSE comparison.
+func (f Field) Is(value bool) *ExpressionCmp {
+	return NewExpressionCmp(OperatorIs, string(f), value)
+}
+
+// IsNot returns an IS NOT TRUE or IS NOT FALSE comparison.
+func (f Field) IsNot(value bool) *ExpressionCmp {
+	return NewExpressionCmp(OperatorIsNot, string(f), value)
+}
+
+// IsDistinct returns a null safe not equal comparison, a nil value compares with NULL.
+func (f Field) IsDistinct(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorIsDistinct, string(f), value)
+}
+
+// NotDistinct returns a null safe equal comparison, a nil value compares with NULL.
+func (f Field) NotDistinct(value any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorNotDistinct, string(f), value)
+}
+
+// KV returns a JSONB containment comparison, value must be a JSON string.
+func (f Field) KV(json string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorKV, string(f), json)
+}
+
+func (f Field) JIn(values ...string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorJIn, string(f), values)
+}
+
+func (f Field) NJIn(values ...string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorNJIn, string(f), values)
+}
+
+func (f Field) JAll(values ...string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorJAll, string(f), values)
+}
+
+// AContains returns an array contains comparison, the array has all the values.
+func (f Field) AContains(values ...any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorAContains, string(f), listValue(values))
+}
+
+// AOverlap returns an array overlap comparison, the array has any of the values.
+func (f Field) AOverlap(values ...any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorAOverlap, string(f), listValue(values))
+}
+
+// AContainedBy returns an array contained by comparison, every item of the array is in the values.
+func (f Field) AContainedBy(values ...any) *ExpressionCmp {
+	return NewExpressionCmp(OperatorAContainedBy, string(f), listValue(values))
+}
+
+// Search returns a full text search comparison.
+func (f Field) Search(text string) *ExpressionCmp {
+	return NewExpressionCmp(OperatorSearch, string(f), text)
+}
+
+// listValue returns []string when all values are strings, otherwise the values as they are.
+func listValue(values []any) any {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return values
+		}
+
+		strs = append(strs, s)
+	}
+
+	return strs
+}
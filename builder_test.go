@@ -0,0 +1,43 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	q := Where(
+		F("age").Gt(18),
+		Or(F("name").ILike("%a%"), F("nick").Eq("b")),
+		F("status").In("active", "pending"),
+		F("id").NIn(1, 2),
+		F("deleted_at").IsNull(),
+	).OrderBy("-age", "name").SetLimit(10)
+
+	text, err := q.MarshalText()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "_sort=age:desc,name&_limit=10&age[gt]=18&(name[ilike]=%25a%25|nick=b)&status[in]=active,pending&id[nin]=1,2&deleted_at[is]="
+	if string(text) != expected {
+		t.Fatalf("unexpected text:\n- want: %s\n-  got: %s", expected, string(text))
+	}
+
+	if !q.HasAny("age", "name", "nick", "status", "id", "deleted_at") || len(q.Values) != 6 {
+		t.Fatalf("unexpected values: %v", q.Values)
+	}
+
+	parsed, err := Parse(string(text))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed.Sort, q.Sort) {
+		t.Fatalf("unexpected sort: %v", parsed.Sort)
+	}
+
+	if got := parsed.GetValues("status"); !reflect.DeepEqual(got, []string{"active", "pending"}) {
+		t.Fatalf("unexpected status values: %v", got)
+	}
+}
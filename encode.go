@@ -0,0 +1,164 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ToValues encodes the query into url.Values.
+//   - Special keys follow the options, e.g. WithUnderscorePrefix(false) uses limit instead of _limit,
+//     otherwise the names kept by Parse or SetSpecialKeys are used.
+//   - Filter keys use the nested style of WithFilterPrefix when it is set.
+//   - Every comparison uses the most compact syntax the parser understands with the same options,
+//     "name=a,b" instead of "name[in]=a,b" and "name=foo" for a key with a matching WithKeyOperator.
+//   - Groups are encoded so that the raw query contains them as "(a=1|b=2)".
+func (q *Query) ToValues(opts ...OptionQuery) url.Values {
+	o := newOptionQuery(opts...)
+	keys := q.specialKeys()
+	if o.hasCustomKeys() {
+		keys = o.resolvedKeys()
+	}
+
+	values := url.Values{}
+
+	if len(q.Select) > 0 {
+		values.Set(keys.Fields, strings.Join(q.Select, ","))
+	}
+
+	if len(q.Group) > 0 {
+		values.Set(keys.Group, strings.Join(q.Group, ","))
+	}
+
+	if len(q.Sort) > 0 {
+		sortParts := make([]string, 0, len(q.Sort))
+		for _, s := range q.Sort {
+			if s.Desc {
+				sortParts = append(sortParts, "-"+s.Field)
+			} else {
+				sortParts = append(sortParts, s.Field)
+			}
+		}
+
+		values.Set(keys.Sort, strings.Join(sortParts, ","))
+	}
+
+	if q.Limit != nil {
+		values.Set(keys.Limit, strconv.FormatUint(*q.Limit, 10))
+	}
+
+	if page, ok := q.page(); ok && keys.Page != "" && q.Offset != nil {
+		values.Set(keys.Page, strconv.FormatUint(page, 10))
+	} else if q.Offset != nil {
+		values.Set(keys.Offset, strconv.FormatUint(*q.Offset, 10))
+	}
+
+	for _, expr := range q.Where {
+		key, value := encodeExpression(expr, o)
+		values.Add(key, value)
+	}
+
+	for _, expr := range q.Having {
+		values.Add(keys.Having, expressionText(expr, false))
+	}
+
+	return values
+}
+
+// EncodeURL sets the query into the raw query of u.
+//   - Existing params of u are kept unless the query sets the same key.
+func (q *Query) EncodeURL(u *url.URL, opts ...OptionQuery) {
+	values := u.Query()
+	for key, v := range q.ToValues(opts...) {
+		values[key] = v
+	}
+
+	u.RawQuery = values.Encode()
+}
+
+// encodeExpression returns the key and the unescaped value of a root expression.
+func encodeExpression(expr Expression, o *optionQuery) (string, string) {
+	if cmp, ok := expr.(*ExpressionCmp); ok {
+		return encodeCmp(cmp, o)
+	}
+
+	// The parser unescapes the whole raw query first, so splitting at the first '='
+	// gives back the group text after decoding.
+	key, value, _ := strings.Cut(encodeText(expr, o), "=")
+
+	return key, value
+}
+
+// encodeText returns the unescaped text of the expression.
+func encodeText(expr Expression, o *optionQuery) string {
+	switch expr := expr.(type) {
+	case *ExpressionCmp:
+		key, value := encodeCmp(expr, o)
+
+		return key + "=" + value
+	case *ExpressionLogic:
+		sep := "&"
+		if expr.Operator == OperatorOr {
+			sep = "|"
+		}
+
+		parts := make([]string, 0, len(expr.List))
+		for _, sub := range expr.List {
+			parts = append(parts, encodeText(sub, o))
+		}
+
+		return "(" + strings.Join(parts, sep) + ")"
+	}
+
+	return expr.String()
+}
+
+// encodeCmp returns the key and the unescaped value of a comparison.
+func encodeCmp(cmp *ExpressionCmp, o *optionQuery) (string, string) {
+	key := cmp.Field
+	if o.FilterPrefix != "" {
+		key = o.FilterPrefix + "[" + key + "]"
+	}
+
+	value := formatValue(cmp.Operator, cmp.Value, false)
+	if compactCmp(cmp, value, o) {
+		return key, value
+	}
+
+	return key + "[" + string(cmp.Operator) + "]", value
+}
+
+// compactCmp reports whether the comparison can be written without the bracket operator.
+func compactCmp(cmp *ExpressionCmp, value string, o *optionQuery) bool {
+	if op, ok := o.KeyOperator[cmp.Field]; ok {
+		return op == cmp.Operator
+	}
+
+	isList := isListValue(cmp.Value)
+	_, commaSplit := o.CommaSplit[cmp.Field]
+
+	switch cmp.Operator {
+	case OperatorEq:
+		if isList {
+			return commaSplit
+		}
+
+		return !strings.Contains(value, ",")
+	case OperatorIn, OperatorEmpty:
+		// A single value would be parsed as eq, keep the operator.
+		return !commaSplit && isList && strings.Contains(value, ",")
+	}
+
+	return false
+}
+
+func isListValue(v any) bool {
+	if v == nil {
+		return false
+	}
+
+	kind := reflect.TypeOf(v).Kind()
+
+	return kind == reflect.Slice || kind == reflect.Array
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestQuery_ToValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		opts  []OptionQuery
+		want  url.Values
+	}{
+		{
+			name:  "compact syntax",
+			value: "name[in]=a,b&age[gt]=1&nick[eq]=x&tags[in]=t&_sort=-age,name&_limit=10&_offset=5&_fields=id,name",
+			want: url.Values{
+				"name":     {"a,b"},
+				"age[gt]":  {"1"},
+				"nick":     {"x"},
+				"tags[in]": {"t"},
+				"_sort":    {"-age,name"},
+				"_limit":   {"10"},
+				"_offset":  {"5"},
+				"_fields":  {"id,name"},
+			},
+		},
+		{
+			name:  "without underscore prefix",
+			value: "limit=10&sort=age",
+			opts:  []OptionQuery{WithUnderscorePrefix(false)},
+			want: url.Values{
+				"limit": {"10"},
+				"sort":  {"age"},
+			},
+		},
+		{
+			name:  "key operator",
+			value: "name[ilike]=%25foo%25&nick[eq]=bar",
+			opts:  []OptionQuery{WithKeyOperator("name", OperatorILike), WithKeyOperator("nick", OperatorILike)},
+			want: url.Values{
+				"name":     {"%foo%"},
+				"nick[eq]": {"bar"},
+			},
+		},
+		{
+			name:  "comma split",
+			value: "name=a,b&nick[in]=c,d",
+			opts:  []OptionQuery{WithCommaSplit("name", "nick")},
+			want: url.Values{
+				"name":     {"a,b"},
+				"nick[in]": {"c,d"},
+			},
+		},
+		{
+			name:  "groups",
+			value: "name=a|nick=b&(x=1|(y=2&z[ne]=3))",
+			want: url.Values{
+				"(name": {"a|nick=b)"},
+				"(x":    {"1|(y=2&z[ne]=3))"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.value, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			values := q.ToValues(tt.opts...)
+			if !reflect.DeepEqual(values, tt.want) {
+				t.Fatalf("ToValues() = %v, want %v", values, tt.want)
+			}
+
+			parsed, err := Parse(values.Encode(), tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse(ToValues()) error = %v", err)
+			}
+
+			if parsed.Fingerprint() != q.Fingerprint() {
+				t.Errorf("Parse(ToValues()) = %v, want %v", parsed.Where, q.Where)
+			}
+		})
+	}
+}
+
+func TestQuery_EncodeURL(t *testing.T) {
+	u, err := url.Parse("http://example.com/users?format=json&_limit=5&name=old")
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	q := Where(F("name").Eq("a b"), F("age").Gte(18)).SetLimit(10)
+	q.EncodeURL(u)
+
+	want := "http://example.com/users?_limit=10&age%5Bgte%5D=18&format=json&name=a+b"
+	if u.String() != want {
+		t.Fatalf("EncodeURL() = %s, want %s", u.String(), want)
+	}
+}
@@ -35,20 +35,40 @@ const (
 	OperatorNLike operatorCmpType = "nlike"
 	// OperatorNILike is the case insensitive not like operator.
 	OperatorNILike operatorCmpType = "nilike"
+	// OperatorIEq is the case insensitive equality operator.
+	OperatorIEq operatorCmpType = "ieq"
+	// OperatorINe is the case insensitive not equal operator.
+	OperatorINe operatorCmpType = "ine"
+	// OperatorIIn is the case insensitive in operator.
+	OperatorIIn operatorCmpType = "iin"
 	// OperatorIn is the in operator.
 	OperatorIn operatorCmpType = "in"
 	// OperatorNIn is the not in operator.
 	OperatorNIn operatorCmpType = "nin"
-	// OperatorIs is the is null operator.
+	// OperatorIs is the is null operator, IS TRUE or IS FALSE for the true and false values.
 	OperatorIs operatorCmpType = "is"
-	// OperatorIsNot is the is not null operator.
+	// OperatorIsNot is the is not null operator, IS NOT TRUE or IS NOT FALSE for the true and false values.
 	OperatorIsNot operatorCmpType = "not"
+	// OperatorIsDistinct is the null safe not equal operator (IS DISTINCT FROM), an empty value is NULL.
+	OperatorIsDistinct operatorCmpType = "isdistinct"
+	// OperatorNotDistinct is the null safe equal operator (IS NOT DISTINCT FROM).
+	OperatorNotDistinct operatorCmpType = "notdistinct"
 	// OperatorKV is the contains operator JSON types.
 	OperatorKV operatorCmpType = "kv"
 	// OperatorJIn is the JSONB array "has any" operator (?|).
 	OperatorJIn operatorCmpType = "jin"
 	// OperatorNJIn is the negated JSONB array "has any" operator (NOT ?|).
 	OperatorNJIn operatorCmpType = "njin"
+	// OperatorJAll is the JSONB array "has all" operator (?&).
+	OperatorJAll operatorCmpType = "jall"
+	// OperatorAContains is the array contains operator (@>), the array has all the values.
+	OperatorAContains operatorCmpType = "acontains"
+	// OperatorAOverlap is the array overlap operator (&&), the array has any of the values.
+	OperatorAOverlap operatorCmpType = "aoverlap"
+	// OperatorAContainedBy is the array contained by operator (<@), every item of the array is in the values.
+	OperatorAContainedBy operatorCmpType = "acontainedby"
+	// OperatorSearch is the full text search operator, the value is the search text.
+	OperatorSearch operatorCmpType = "search"
 )
 
 type operatorLogicType string
@@ -76,12 +96,17 @@ func (e *ExpressionCmp) Expression() Expression {
 }
 
 func (e ExpressionCmp) String() string {
+	return e.text(true)
+}
+
+// text formats the comparison, values are URL escaped when escape is true.
+func (e ExpressionCmp) text(escape bool) string {
 	key := e.Field
 	if e.Operator != OperatorEq {
 		key += "[" + string(e.Operator) + "]"
 	}
 
-	val := formatValue(e.Operator, e.Value)
+	val := formatValue(e.Operator, e.Value, escape)
 
 	return key + "=" + val
 }
@@ -96,6 +121,11 @@ func (e *ExpressionLogic) Expression() Expression {
 }
 
 func (e ExpressionLogic) String() string {
+	return e.text(true)
+}
+
+// text formats the group, values are URL escaped when escape is true.
+func (e ExpressionLogic) text(escape bool) string {
 	if e.Operator == OperatorOr {
 		// Check if all are ExpressionCmp with same field
 		if len(e.List) > 0 {
@@ -105,7 +135,7 @@ func (e ExpressionLogic) String() string {
 				allSame := true
 				for _, sub := range e.List {
 					if c, ok := sub.(*ExpressionCmp); ok && c.Field == field && c.Operator == OperatorEq {
-						values = append(values, formatValue(c.Operator, c.Value))
+						values = append(values, formatValue(c.Operator, c.Value, escape))
 					} else {
 						allSame = false
 						break
@@ -124,7 +154,7 @@ func (e ExpressionLogic) String() string {
 	}
 	parts := make([]string, len(e.List))
 	for i, sub := range e.List {
-		parts[i] = sub.String()
+		parts[i] = expressionText(sub, escape)
 	}
 	joined := strings.Join(parts, sep)
 	if e.Operator == OperatorOr || e.Operator == OperatorAnd {
@@ -134,27 +164,49 @@ func (e ExpressionLogic) String() string {
 	return joined
 }
 
-func formatValue(operator operatorCmpType, v any) string {
+// expressionText formats the expression, values are URL escaped when escape is true.
+func expressionText(expr Expression, escape bool) string {
+	switch expr := expr.(type) {
+	case *ExpressionCmp:
+		return expr.text(escape)
+	case *ExpressionLogic:
+		return expr.text(escape)
+	}
+
+	return expr.String()
+}
+
+func formatValue(operator operatorCmpType, v any, escape bool) string {
 	if operator == OperatorKV {
 		vStr, _ := v.(string)
 
 		return Base64URLEncode([]byte(vStr))
 	}
 
+	if v == nil {
+		return ""
+	}
+
 	if s, ok := v.(string); ok {
+		if !escape {
+			return s
+		}
+
 		return url.QueryEscape(s)
 	}
 
-	if ss, ok := v.([]string); ok {
-		escaped := make([]string, len(ss))
-		for i, s := range ss {
-			escaped[i] = url.QueryEscape(s)
-		}
+	// Lists, including typed ones like []bool or []int, are joined with commas.
+	ss := cmpValues(&ExpressionCmp{Value: v})
+	if !escape {
+		return strings.Join(ss, ",")
+	}
 
-		return strings.Join(escaped, ",")
+	escaped := make([]string, len(ss))
+	for i, s := range ss {
+		escaped[i] = url.QueryEscape(s)
 	}
 
-	return url.QueryEscape(fmt.Sprintf("%v", v))
+	return strings.Join(escaped, ",")
 }
 
 type ExpressionSort struct {
@@ -206,7 +258,8 @@ func getKey(input string) string {
 
 // ParseExpression parses a single expression from key-value pairs.
 //   - key -> key[eq]
-//   - eq, ne, gt, lt, gte, lte, like, ilike, nlike, nilike, in, nin, is, not, kv
+//   - eq, ne, gt, lt, gte, lte, like, ilike, nlike, nilike, in, nin, is, not, kv, jin, njin, jall, search
+//   - acontains, aoverlap, acontainedby, isdistinct, notdistinct, ieq, ine, iin
 func ParseExpression(key, value string, valueType ValueType) (*ExpressionCmp, error) {
 	return parseExpression(key, value, valueType, nil, nil, nil)
 }
@@ -257,10 +310,12 @@ func parseExpression(key, value string, valueType ValueType, keyOperator map[str
 }
 
 // isCommaSplitOperator returns true if the operator supports comma splitting.
-// Operators that already handle commas natively (in, nin, jin, njin) and special operators (is, not, kv) are excluded.
+// Operators that already handle commas natively (in, nin, iin, jin, njin, jall and array operators)
+// and special operators (is, not, kv, search) are excluded.
 func isCommaSplitOperator(op operatorCmpType) bool {
 	switch op {
-	case OperatorIn, OperatorNIn, OperatorJIn, OperatorNJIn, OperatorIs, OperatorIsNot, OperatorKV, OperatorEmpty:
+	case OperatorIn, OperatorNIn, OperatorIIn, OperatorJIn, OperatorNJIn, OperatorJAll, OperatorIs, OperatorIsNot, OperatorKV, OperatorSearch, OperatorEmpty,
+		OperatorAContains, OperatorAOverlap, OperatorAContainedBy:
 		return false
 	default:
 		return true
@@ -352,6 +407,11 @@ func ParseExpressionWithOperator(operator string, key string, value string, valu
 		return NewExpressionCmp(OperatorNLike, key, value), nil
 	case OperatorNILike:
 		return NewExpressionCmp(OperatorNILike, key, value), nil
+	case OperatorIEq, OperatorINe:
+		// Case insensitive comparisons are for strings, the value is not typed.
+		return NewExpressionCmp(operatorCmpType(operator), key, value), nil
+	case OperatorIIn:
+		return NewExpressionCmp(OperatorIIn, key, strings.Split(value, ",")), nil
 	case OperatorIn, OperatorEmpty:
 		if strings.Contains(value, ",") {
 			v, err := StringsToType(strings.Split(value, ","), valueType)
@@ -384,10 +444,25 @@ func ParseExpressionWithOperator(operator string, key string, value string, valu
 		}
 
 		return NewExpressionCmp(OperatorNIn, key, v), nil
-	case OperatorIs:
-		return NewExpressionCmp(OperatorIs, key, nil), nil
-	case OperatorIsNot:
-		return NewExpressionCmp(OperatorIsNot, key, nil), nil
+	case OperatorIs, OperatorIsNot:
+		v, err := parseIsValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewExpressionCmp(operatorCmpType(operator), key, v), nil
+	case OperatorIsDistinct, OperatorNotDistinct:
+		// An empty value compares with NULL, like the is operator.
+		if value == "" {
+			return NewExpressionCmp(operatorCmpType(operator), key, nil), nil
+		}
+
+		v, err := StringToType(value, valueType)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewExpressionCmp(operatorCmpType(operator), key, v), nil
 	case OperatorKV:
 		// check it is a valid JSON string
 		if json.Valid([]byte(value)) {
@@ -411,7 +486,36 @@ func ParseExpressionWithOperator(operator string, key string, value string, valu
 	case OperatorNJIn:
 		values := strings.Split(value, ",")
 		return NewExpressionCmp(OperatorNJIn, key, values), nil
+	case OperatorJAll:
+		values := strings.Split(value, ",")
+		return NewExpressionCmp(OperatorJAll, key, values), nil
+	case OperatorAContains, OperatorAOverlap, OperatorAContainedBy:
+		// The value is always a list, items are typed with the value type.
+		v, err := arrayItems(strings.Split(value, ","), valueType)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewExpressionCmp(operatorCmpType(operator), key, v), nil
+	case OperatorSearch:
+		// The search text is kept as it is, words and commas are handled by the adapter.
+		return NewExpressionCmp(OperatorSearch, key, value), nil
 	}
 
 	return nil, fmt.Errorf("unsupported operator: [%s]", operator)
 }
+
+// parseIsValue returns the value of the is and not operators.
+//   - Empty and null values are NULL, true and false are booleans.
+func parseIsValue(value string) (any, error) {
+	switch value {
+	case "", "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	return nil, fmt.Errorf("invalid value [%s] for is operator, use null, true or false", value)
+}
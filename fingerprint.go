@@ -0,0 +1,145 @@
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type optionFingerprint struct {
+	IgnorePagination bool
+	Shape            bool
+}
+
+type OptionFingerprint func(*optionFingerprint)
+
+// WithFingerprintIgnorePagination excludes limit and offset from the fingerprint.
+//   - Useful to share count caches between pages of the same filter.
+func WithFingerprintIgnorePagination() OptionFingerprint {
+	return func(o *optionFingerprint) {
+		o.IgnorePagination = true
+	}
+}
+
+// WithFingerprintShape strips the values from the fingerprint and keeps only the query shape.
+//   - "age[gt]=18" and "age[gt]=30" have the same shape.
+//   - Useful to group slow query metrics.
+func WithFingerprintShape() OptionFingerprint {
+	return func(o *optionFingerprint) {
+		o.Shape = true
+	}
+}
+
+// Fingerprint returns a stable hex encoded SHA-256 hash of the query.
+// The hash is computed from a canonical encoding of the normalized Where, Sort, Select, Group, Having, Limit and Offset,
+// so equivalent queries have the same fingerprint. The query itself is not changed.
+func (q *Query) Fingerprint(opts ...OptionFingerprint) string {
+	o := &optionFingerprint{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	sum := sha256.Sum256([]byte(q.canonical(o)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// canonical returns the canonical text encoding of the query used by Fingerprint.
+func (q *Query) canonical(o *optionFingerprint) string {
+	c := q.Clone().Normalize()
+
+	var b strings.Builder
+
+	b.WriteString("where:")
+	if o.Shape {
+		b.WriteString(strings.Join(shapeStrings(c.Where), "&"))
+	} else {
+		for i, expr := range c.Where {
+			if i > 0 {
+				b.WriteByte('&')
+			}
+
+			b.WriteString(expr.String())
+		}
+	}
+
+	b.WriteString("\nselect:")
+	selects := slices.Clone(c.Select)
+	slices.Sort(selects)
+	b.WriteString(strings.Join(slices.Compact(selects), ","))
+
+	b.WriteString("\ngroup:")
+	b.WriteString(strings.Join(c.Group, ","))
+
+	b.WriteString("\nhaving:")
+	if o.Shape {
+		b.WriteString(strings.Join(shapeStrings(c.Having), "&"))
+	} else {
+		having := make([]string, 0, len(c.Having))
+		for _, expr := range c.Having {
+			having = append(having, expr.String())
+		}
+		slices.Sort(having)
+		b.WriteString(strings.Join(having, "&"))
+	}
+
+	b.WriteString("\nsort:")
+	for i, s := range c.Sort {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		b.WriteString(s.Field)
+		if s.Desc {
+			b.WriteString(":desc")
+		}
+	}
+
+	if !o.IgnorePagination {
+		b.WriteString("\nlimit:")
+		writeCanonicalUint(&b, c.Limit, o.Shape)
+		b.WriteString("\noffset:")
+		writeCanonicalUint(&b, c.Offset, o.Shape)
+	}
+
+	return b.String()
+}
+
+// shapeString returns the expression without values, children are sorted by their shape.
+func shapeString(expr Expression) string {
+	switch expr := expr.(type) {
+	case *ExpressionCmp:
+		return expr.Field + "[" + string(expr.Operator) + "]=?"
+	case *ExpressionLogic:
+		sep := "&"
+		if expr.Operator == OperatorOr {
+			sep = "|"
+		}
+
+		return "(" + strings.Join(shapeStrings(expr.List), sep) + ")"
+	}
+
+	return ""
+}
+
+func shapeStrings(list []Expression) []string {
+	parts := make([]string, 0, len(list))
+	for _, e := range list {
+		parts = append(parts, shapeString(e))
+	}
+	slices.Sort(parts)
+
+	return parts
+}
+
+func writeCanonicalUint(b *strings.Builder, v *uint64, shape bool) {
+	switch {
+	case v == nil:
+	case shape:
+		b.WriteByte('?')
+	default:
+		b.WriteString(strconv.FormatUint(*v, 10))
+	}
+}
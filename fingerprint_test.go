@@ -0,0 +1,90 @@
+package query
+
+import "testing"
+
+func TestQuery_Fingerprint(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     string
+		b     string
+		opts  []OptionFingerprint
+		equal bool
+	}{
+		{
+			name:  "equivalent filters",
+			a:     "name=a|name=b&age[gt]=1&_limit=10",
+			b:     "age[gt]=1&name[in]=b,a&_limit=10",
+			equal: true,
+		},
+		{
+			name:  "select order",
+			a:     "_fields=id,name",
+			b:     "_fields=name,id",
+			equal: true,
+		},
+		{
+			name:  "different values",
+			a:     "age[gt]=1",
+			b:     "age[gt]=2",
+			equal: false,
+		},
+		{
+			name:  "different sort",
+			a:     "_sort=age,name",
+			b:     "_sort=name,age",
+			equal: false,
+		},
+		{
+			name:  "different page",
+			a:     "name=a&_limit=10&_offset=0",
+			b:     "name=a&_limit=10&_offset=10",
+			equal: false,
+		},
+		{
+			name:  "different page ignore pagination",
+			a:     "name=a&_limit=10&_offset=0",
+			b:     "name=a&_limit=10&_offset=10",
+			opts:  []OptionFingerprint{WithFingerprintIgnorePagination()},
+			equal: true,
+		},
+		{
+			name:  "same shape",
+			a:     "age[gt]=1&(name=foo|nick=x)&_limit=10",
+			b:     "(nick=y|name=bar)&age[gt]=30&_limit=20",
+			opts:  []OptionFingerprint{WithFingerprintShape()},
+			equal: true,
+		},
+		{
+			name:  "different shape",
+			a:     "age[gt]=1",
+			b:     "age[lt]=1",
+			opts:  []OptionFingerprint{WithFingerprintShape()},
+			equal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qa, err := Parse(tt.a)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			qb, err := Parse(tt.b)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			textBefore, _ := qa.MarshalText()
+
+			fa, fb := qa.Fingerprint(tt.opts...), qb.Fingerprint(tt.opts...)
+			if (fa == fb) != tt.equal {
+				t.Errorf("Fingerprint() equal = %v, want %v", fa == fb, tt.equal)
+			}
+
+			if textAfter, _ := qa.MarshalText(); string(textAfter) != string(textBefore) {
+				t.Errorf("Fingerprint() changed the query: %s, want %s", textAfter, textBefore)
+			}
+		})
+	}
+}
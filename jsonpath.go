@@ -0,0 +1,27 @@
+package query
+
+import "strings"
+
+// ParseJSONPath splits a JSON path field into the column and the keys, false when the field is not a JSON path.
+//   - meta->color -> meta, [color]
+//   - meta->address->city -> meta, [address city]
+//   - meta->>color is accepted like meta->color.
+//   - Array items use the index as key, tags->0.
+func ParseJSONPath(field string) (string, []string, bool) {
+	column, rest, ok := strings.Cut(field, "->")
+	if !ok || column == "" {
+		return "", nil, false
+	}
+
+	path := strings.Split(rest, "->")
+	for i, key := range path {
+		key = strings.TrimPrefix(key, ">")
+		if key == "" {
+			return "", nil, false
+		}
+
+		path[i] = key
+	}
+
+	return column, path, true
+}
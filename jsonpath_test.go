@@ -0,0 +1,51 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		field      string
+		wantColumn string
+		wantPath   []string
+		wantOK     bool
+	}{
+		{field: "meta->color", wantColumn: "meta", wantPath: []string{"color"}, wantOK: true},
+		{field: "meta->>color", wantColumn: "meta", wantPath: []string{"color"}, wantOK: true},
+		{field: "meta->address->city", wantColumn: "meta", wantPath: []string{"address", "city"}, wantOK: true},
+		{field: "tags->0", wantColumn: "tags", wantPath: []string{"0"}, wantOK: true},
+		{field: "meta"},
+		{field: "meta.color"},
+		{field: "->color"},
+		{field: "meta->"},
+		{field: "meta->a->->b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			column, path, ok := ParseJSONPath(tt.field)
+			if ok != tt.wantOK || column != tt.wantColumn || !reflect.DeepEqual(path, tt.wantPath) {
+				t.Fatalf("ParseJSONPath() = %q, %v, %v, want %q, %v, %v", column, path, ok, tt.wantColumn, tt.wantPath, tt.wantOK)
+			}
+		})
+	}
+
+	q, err := Parse("meta->color=red&meta->size[gt]=10&_sort=-meta->size")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Expression{
+		NewExpressionCmp(OperatorEq, "meta->color", "red"),
+		NewExpressionCmp(OperatorGt, "meta->size", "10"),
+	}
+	if !reflect.DeepEqual(q.Where, want) {
+		t.Fatalf("Parse() Where = %v, want %v", q.Where, want)
+	}
+
+	if want := []ExpressionSort{{Field: "meta->size", Desc: true}}; !reflect.DeepEqual(q.Sort, want) {
+		t.Fatalf("Parse() Sort = %v, want %v", q.Sort, want)
+	}
+}
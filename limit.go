@@ -0,0 +1,93 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded is the base error of all complexity limit violations.
+//   - Use errors.Is(err, ErrLimitExceeded) to detect them.
+var ErrLimitExceeded = errors.New("query limit exceeded")
+
+// LimitType is the name of a complexity limit.
+type LimitType string
+
+const (
+	LimitDepth       LimitType = "depth"
+	LimitComparisons LimitType = "comparisons"
+	LimitOrBranches  LimitType = "or branches"
+	LimitListItems   LimitType = "list items"
+	LimitQueryLength LimitType = "query length"
+)
+
+// LimitError is returned when a query exceeds a complexity limit.
+type LimitError struct {
+	Limit LimitType
+	Max   int
+	Value int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s [%d] is greater than max [%d]", e.Limit, e.Value, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// complexity holds the size of the where tree of a query.
+type complexity struct {
+	Depth       int
+	Comparisons int
+	OrBranches  int
+	ListItems   int
+}
+
+// complexity walks the where tree and measures it.
+//   - Depth is the nesting depth of expression groups, see expressionDepth.
+//   - ListItems is the largest number of values in a single comparison.
+func (q *Query) complexity() complexity {
+	var c complexity
+
+	depth := 0
+	_ = q.Walk(func(t Token) error {
+		switch t.Type {
+		case WalkStart:
+			depth++
+			c.Depth = max(c.Depth, depth)
+
+			if e, ok := t.Expression.(*ExpressionLogic); ok && e.Operator == OperatorOr {
+				c.OrBranches += len(e.List)
+			}
+		case WalkEnd:
+			depth--
+		case WalkCurrent:
+			if cmp, ok := t.Expression.(*ExpressionCmp); ok {
+				c.Comparisons++
+				c.ListItems = max(c.ListItems, len(cmpValues(cmp)))
+			}
+		}
+
+		return nil
+	})
+
+	return c
+}
+
+// expressionDepth returns the nesting depth of the groups of an expression.
+// It is the depth used by WithMaxDepth and WithDepth, the groups of the parsed tree:
+//   - a=1 is 0, a=1|b=2 and ((a=1)) are 1, the outer parentheses of a pair are not a group.
+//   - a=1|(b=2&c=3) is 2, the AND group is inside the OR group.
+func expressionDepth(e Expression) int {
+	logic, ok := e.(*ExpressionLogic)
+	if !ok {
+		return 0
+	}
+
+	depth := 0
+	for _, sub := range logic.List {
+		depth = max(depth, expressionDepth(sub))
+	}
+
+	return depth + 1
+}
@@ -0,0 +1,184 @@
+package query
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestParseWithLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		opts      []OptionQuery
+		wantLimit LimitType
+	}{
+		{
+			name:  "depth ok",
+			value: "(a=1|(b=2&c=3))",
+			opts:  []OptionQuery{WithMaxDepth(2)},
+		},
+		{
+			name:      "depth exceeded",
+			value:     "(a=1|(b=2&(c=3|d=4)))",
+			opts:      []OptionQuery{WithMaxDepth(2)},
+			wantLimit: LimitDepth,
+		},
+		{
+			name:  "comparisons ok",
+			value: "a=1&b=2|c=3",
+			opts:  []OptionQuery{WithMaxComparisons(3)},
+		},
+		{
+			name:      "comparisons exceeded",
+			value:     "a=1&b=2|c=3&(d=4|e=5)",
+			opts:      []OptionQuery{WithMaxComparisons(3)},
+			wantLimit: LimitComparisons,
+		},
+		{
+			name:      "or branches exceeded",
+			value:     "a=1|b=2&(c=3|d=4|e=5)",
+			opts:      []OptionQuery{WithMaxOrBranches(4)},
+			wantLimit: LimitOrBranches,
+		},
+		{
+			name:  "list items ok",
+			value: "a=1,2,3&b[jin]=x,y",
+			opts:  []OptionQuery{WithMaxListItems(3)},
+		},
+		{
+			name:      "list items exceeded",
+			value:     "b[jin]=w,x,y,z",
+			opts:      []OptionQuery{WithMaxListItems(3)},
+			wantLimit: LimitListItems,
+		},
+		{
+			name:      "query length exceeded",
+			value:     "name=abcdefghij",
+			opts:      []OptionQuery{WithMaxQueryLength(10)},
+			wantLimit: LimitQueryLength,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.value, tt.opts...)
+			if tt.wantLimit == "" {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("Parse() error = %v, want ErrLimitExceeded", err)
+			}
+
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.wantLimit {
+				t.Fatalf("Parse() error = %v, want limit %s", err, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestValidateLimits(t *testing.T) {
+	validator, err := NewValidator(
+		WithDepth(WithMax("2")),
+		WithComparisons(WithMax("4")),
+		WithOrBranches(WithMax("3")),
+		WithListItems(WithMax("2")),
+	)
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	tests := []struct {
+		value     string
+		wantLimit LimitType
+	}{
+		{value: "a=1|b=2&c=1,2"},
+		{value: "(a=1|(b=2&(c=3|d=4)))", wantLimit: LimitDepth},
+		{value: "a=1&b=2&c=3&d=4&e=5", wantLimit: LimitComparisons},
+		{value: "a=1|b=2&c=3|d=4", wantLimit: LimitOrBranches},
+		{value: "a=1,2,3", wantLimit: LimitListItems},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.value)
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", tt.value, err)
+		}
+
+		err = q.Validate(validator)
+		if tt.wantLimit == "" {
+			if err != nil {
+				t.Errorf("Validate(%s) error = %v", tt.value, err)
+			}
+
+			continue
+		}
+
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != tt.wantLimit {
+			t.Errorf("Validate(%s) error = %v, want limit %s", tt.value, err, tt.wantLimit)
+		}
+	}
+}
+
+func TestDepthParseAndValidate(t *testing.T) {
+	tests := []struct {
+		value string
+		depth int
+	}{
+		{value: "a=1", depth: 0},
+		{value: "a=1|b=2", depth: 1},
+		{value: "name=foo|bar", depth: 1},
+		{value: "((a=1))", depth: 1},
+		{value: "(a=1&b=2)", depth: 1},
+		{value: "a=1|(b=2&c=3)", depth: 2},
+		{value: "(a=1|(b=2&(c=3|d=4)))", depth: 4},
+		{value: "(((a=1|b=2)))", depth: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			q, err := Parse(tt.value)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if d := q.complexity().Depth; d != tt.depth {
+				t.Fatalf("complexity() depth = %d, want %d", d, tt.depth)
+			}
+
+			if _, err := Parse(tt.value, WithMaxDepth(tt.depth)); err != nil {
+				t.Fatalf("Parse() with max depth %d error = %v", tt.depth, err)
+			}
+
+			// 0 is no limit for the parser.
+			if tt.depth < 2 {
+				return
+			}
+
+			validator, err := NewValidator(WithDepth(WithMax(strconv.Itoa(tt.depth - 1))))
+			if err != nil {
+				t.Fatalf("NewValidator() error = %v", err)
+			}
+
+			var parseErr, validateErr *LimitError
+			if _, err := Parse(tt.value, WithMaxDepth(tt.depth-1)); !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() with max depth %d error = %v, want *LimitError", tt.depth-1, err)
+			}
+
+			if err := q.Validate(validator); !errors.As(err, &validateErr) {
+				t.Fatalf("Validate() with max depth %d error = %v, want *LimitError", tt.depth-1, err)
+			}
+
+			if parseErr.Limit != LimitDepth || parseErr.Value != tt.depth || *validateErr != *parseErr {
+				t.Fatalf("Parse() error = %#v, Validate() error = %#v, want depth %d", parseErr, validateErr, tt.depth)
+			}
+		})
+	}
+}
@@ -8,16 +8,31 @@ import (
 	"strings"
 )
 
+// MarshalText encodes the query into the query string syntax.
+//   - Special keys use the names of WithSpecialKeys or SetSpecialKeys.
+//   - With a page key the offset is written as a page number when it is at a page boundary.
 func (q *Query) MarshalText() ([]byte, error) {
+	keys := q.specialKeys()
+
 	// convert to url.Values
 	values := bytes.Buffer{}
 
 	if len(q.Select) > 0 {
-		values.WriteString(keyFields)
+		values.WriteString(keys.Fields)
 		values.WriteString("=")
 		values.WriteString(strings.Join(q.Select, ","))
 	}
 
+	if len(q.Group) > 0 {
+		if values.Len() > 0 {
+			values.WriteString("&")
+		}
+
+		values.WriteString(keys.Group)
+		values.WriteString("=")
+		values.WriteString(strings.Join(q.Group, ","))
+	}
+
 	if len(q.Sort) > 0 {
 		if values.Len() > 0 {
 			values.WriteString("&")
@@ -33,7 +48,7 @@ func (q *Query) MarshalText() ([]byte, error) {
 			sortParts = append(sortParts, fmt.Sprintf("%s%s", s.Field, decs))
 		}
 
-		values.WriteString(keySort)
+		values.WriteString(keys.Sort)
 		values.WriteString("=")
 		values.WriteString(strings.Join(sortParts, ","))
 	}
@@ -43,17 +58,25 @@ func (q *Query) MarshalText() ([]byte, error) {
 			values.WriteString("&")
 		}
 
-		values.WriteString(keyLimit)
+		values.WriteString(keys.Limit)
 		values.WriteString("=")
 		values.WriteString(strconv.FormatUint(*q.Limit, 10))
 	}
 
-	if q.Offset != nil {
+	if page, ok := q.page(); ok && keys.Page != "" && q.Offset != nil {
 		if values.Len() > 0 {
 			values.WriteString("&")
 		}
 
-		values.WriteString(keyOffset)
+		values.WriteString(keys.Page)
+		values.WriteString("=")
+		values.WriteString(strconv.FormatUint(page, 10))
+	} else if q.Offset != nil {
+		if values.Len() > 0 {
+			values.WriteString("&")
+		}
+
+		values.WriteString(keys.Offset)
 		values.WriteString("=")
 		values.WriteString(strconv.FormatUint(*q.Offset, 10))
 	}
@@ -66,6 +89,16 @@ func (q *Query) MarshalText() ([]byte, error) {
 		values.WriteString(expr.String())
 	}
 
+	for _, expr := range q.Having {
+		if values.Len() > 0 {
+			values.WriteString("&")
+		}
+
+		values.WriteString(keys.Having)
+		values.WriteString("=")
+		values.WriteString(expr.String())
+	}
+
 	return values.Bytes(), nil
 }
 
@@ -0,0 +1,196 @@
+package query
+
+import "slices"
+
+// MergeStrategy selects how a part of two queries is merged.
+type MergeStrategy int
+
+const (
+	// MergeDefault uses the default strategy of the part, see MergePolicy.
+	MergeDefault MergeStrategy = iota
+	// MergeBase keeps the value of the base query.
+	MergeBase
+	// MergeOther uses the value of the other query when it is set, otherwise the base value.
+	MergeOther
+	// MergeIntersect keeps only fields existing in both queries, an empty side means all fields.
+	//   - Usable for Select.
+	//   - When nothing is common the base selection is kept.
+	MergeIntersect
+	// MergeUnion keeps the fields of both queries, base first.
+	//   - Usable for Select and Sort.
+	MergeUnion
+	// MergeMin uses the minimum of the values set.
+	//   - Usable for Limit and Offset.
+	MergeMin
+	// MergeMax uses the maximum of the values set.
+	//   - Usable for Limit and Offset.
+	MergeMax
+)
+
+// MergePolicy says which value wins when two queries are merged.
+//   - Select defaults to MergeIntersect.
+//   - Sort defaults to MergeOther.
+//   - Limit defaults to MergeMin.
+//   - Offset defaults to MergeOther.
+//
+// Where and Having clauses are always combined with AND, so the other query can only narrow the base query.
+// Group keeps the base fields, the fields of the other query are used when the base has none.
+type MergePolicy struct {
+	Select MergeStrategy
+	Sort   MergeStrategy
+	Limit  MergeStrategy
+	Offset MergeStrategy
+}
+
+// And merges the queries with the default MergePolicy, nil queries are skipped.
+//
+//	q := query.And(serverScope, userQuery)
+func And(queries ...*Query) *Query {
+	result := New()
+	for _, q := range queries {
+		result = result.Merge(q, MergePolicy{})
+	}
+
+	return result
+}
+
+// Merge returns a new query with the where clauses of both queries combined with AND.
+// Select, Sort, Limit and Offset are chosen by the policy. Neither query is changed.
+func (q *Query) Merge(other *Query, policy MergePolicy) *Query {
+	result := q.Clone()
+	if result == nil {
+		result = New()
+	}
+
+	if other == nil {
+		return result
+	}
+
+	other = other.Clone()
+
+	result.Where = append(result.Where, other.Where...)
+
+	for field, values := range other.Values {
+		if result.Values == nil {
+			result.Values = make(map[string][]*ExpressionCmp)
+		}
+
+		result.Values[field] = append(result.Values[field], values...)
+	}
+
+	result.Having = append(result.Having, other.Having...)
+	if len(result.Group) == 0 {
+		result.Group = other.Group
+	}
+
+	result.Select = mergeSelect(result.Select, other.Select, policy.Select)
+	result.Sort = mergeSort(result.Sort, other.Sort, policy.Sort)
+	result.Limit = mergeUint(result.Limit, other.Limit, policy.Limit, MergeMin)
+	result.Offset = mergeUint(result.Offset, other.Offset, policy.Offset, MergeOther)
+
+	return result
+}
+
+func mergeSelect(base, other []string, strategy MergeStrategy) []string {
+	switch strategy {
+	case MergeBase:
+		return base
+	case MergeOther:
+		if len(other) > 0 {
+			return other
+		}
+
+		return base
+	case MergeUnion:
+		result := base
+		for _, field := range other {
+			if !slices.Contains(result, field) {
+				result = append(result, field)
+			}
+		}
+
+		return result
+	default:
+		if len(base) == 0 {
+			return other
+		}
+
+		if len(other) == 0 {
+			return base
+		}
+
+		// Keep the order of the other query, the base is the allowed set.
+		result := make([]string, 0, len(other))
+		for _, field := range other {
+			if slices.Contains(base, field) && !slices.Contains(result, field) {
+				result = append(result, field)
+			}
+		}
+
+		// An empty select means all fields, never widen the base selection.
+		if len(result) == 0 {
+			return base
+		}
+
+		return result
+	}
+}
+
+func mergeSort(base, other []ExpressionSort, strategy MergeStrategy) []ExpressionSort {
+	switch strategy {
+	case MergeBase:
+		return base
+	case MergeUnion:
+		result := base
+		for _, s := range other {
+			if !slices.ContainsFunc(result, func(r ExpressionSort) bool { return r.Field == s.Field }) {
+				result = append(result, s)
+			}
+		}
+
+		return result
+	default:
+		if len(other) > 0 {
+			return other
+		}
+
+		return base
+	}
+}
+
+func mergeUint(base, other *uint64, strategy, defaultStrategy MergeStrategy) *uint64 {
+	if strategy == MergeDefault {
+		strategy = defaultStrategy
+	}
+
+	if base == nil {
+		if strategy == MergeBase {
+			return nil
+		}
+
+		return other
+	}
+
+	if other == nil {
+		return base
+	}
+
+	switch strategy {
+	case MergeBase:
+		return base
+	case MergeMin:
+		if *other < *base {
+			return other
+		}
+
+		return base
+	case MergeMax:
+		if *other > *base {
+			return other
+		}
+
+		return base
+	default:
+		return other
+	}
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuery_Merge(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		other    string
+		policy   MergePolicy
+		wantText string
+	}{
+		{
+			name:     "default policy",
+			base:     "tenant_id=1&deleted=false&_fields=id,name,age&_limit=100",
+			other:    "(tenant_id=2|name=foo)&_fields=age,secret,id&_limit=500&_offset=20&_sort=-age",
+			wantText: "_fields=age,id&_sort=age:desc&_limit=100&_offset=20&tenant_id=1&deleted=false&(tenant_id=2|name=foo)",
+		},
+		{
+			name:     "intersect empty keeps base",
+			base:     "_fields=id,name",
+			other:    "_fields=secret",
+			wantText: "_fields=id,name",
+		},
+		{
+			name:     "base select empty",
+			base:     "tenant_id=1",
+			other:    "_fields=id",
+			wantText: "_fields=id&tenant_id=1",
+		},
+		{
+			name:     "min limit when other is lower",
+			base:     "_limit=100",
+			other:    "_limit=10",
+			wantText: "_limit=10",
+		},
+		{
+			name:  "custom policy",
+			base:  "_fields=id&_sort=name&_limit=10&_offset=5",
+			other: "_fields=age&_sort=-age,name&_limit=50&_offset=10",
+			policy: MergePolicy{
+				Select: MergeUnion,
+				Sort:   MergeUnion,
+				Limit:  MergeMax,
+				Offset: MergeBase,
+			},
+			wantText: "_fields=id,age&_sort=name,age:desc&_limit=50&_offset=5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := Parse(tt.base)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			other, err := Parse(tt.other)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			baseText, _ := base.MarshalText()
+
+			merged := base.Merge(other, tt.policy)
+
+			text, err := merged.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.wantText {
+				t.Errorf("Merge() = %s, want %s", text, tt.wantText)
+			}
+
+			if afterText, _ := base.MarshalText(); string(afterText) != string(baseText) {
+				t.Errorf("Merge() changed the base query: %s", afterText)
+			}
+		})
+	}
+}
+
+func TestAnd(t *testing.T) {
+	scope := New().AddWhere(NewExpressionCmp(OperatorEq, "tenant_id", "1")).SetLimit(100)
+
+	user, err := Parse("tenant_id=2|name=foo&_limit=1000")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	q := And(scope, nil, user)
+
+	text, err := q.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+
+	if want := "_limit=100&tenant_id=1&(tenant_id=2|name=foo)"; string(text) != want {
+		t.Errorf("And() = %s, want %s", text, want)
+	}
+
+	if got := q.GetValues("tenant_id"); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("And() Values = %v, want [1 2]", got)
+	}
+}
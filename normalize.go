@@ -0,0 +1,153 @@
+package query
+
+import (
+	"slices"
+	"strings"
+)
+
+// Normalize simplifies the where tree into a canonical form.
+//   - Nested groups with the same operator are flattened, AND inside AND and OR inside OR.
+//   - Groups with a single child are replaced by the child.
+//   - Same field equality comparisons inside an OR are merged into an IN comparison.
+//   - Duplicate comparisons are removed and list values are sorted.
+//   - Children of every group, and the root, are sorted into a canonical order.
+//
+// Two equivalent queries produce the same MarshalText output after normalization.
+func (q *Query) Normalize() *Query {
+	if len(q.Where) == 0 {
+		return q
+	}
+
+	// Handle the root as an AND group to share the same rules.
+	q.Where = []Expression{NewExpressionLogic(OperatorAnd, q.Where)}
+
+	_ = q.Transform(func(e Expression) (Expression, error) {
+		switch e := e.(type) {
+		case *ExpressionCmp:
+			return normalizeCmp(e), nil
+		case *ExpressionLogic:
+			return normalizeLogic(e), nil
+		}
+
+		return e, nil
+	})
+
+	if len(q.Where) == 1 {
+		if root, ok := q.Where[0].(*ExpressionLogic); ok && root.Operator == OperatorAnd {
+			q.Where = root.List
+		}
+	}
+
+	return q
+}
+
+// normalizeCmp sorts and deduplicates the values of list operators.
+func normalizeCmp(e *ExpressionCmp) Expression {
+	switch e.Operator {
+	case OperatorIn, OperatorNIn, OperatorIIn, OperatorJIn, OperatorNJIn, OperatorJAll, OperatorAContains, OperatorAOverlap, OperatorAContainedBy:
+		if values, ok := e.Value.([]string); ok {
+			values = slices.Clone(values)
+			slices.Sort(values)
+			e.Value = slices.Compact(values)
+		}
+	}
+
+	return e
+}
+
+func normalizeLogic(e *ExpressionLogic) Expression {
+	list := make([]Expression, 0, len(e.List))
+	for _, sub := range e.List {
+		if subLogic, ok := sub.(*ExpressionLogic); ok && subLogic.Operator == e.Operator {
+			list = append(list, subLogic.List...)
+
+			continue
+		}
+
+		list = append(list, sub)
+	}
+
+	if e.Operator == OperatorOr {
+		list = mergeEqualities(list)
+	}
+
+	keys := make(map[Expression]string, len(list))
+	for _, sub := range list {
+		keys[sub] = sub.String()
+	}
+
+	slices.SortStableFunc(list, func(a, b Expression) int {
+		return strings.Compare(keys[a], keys[b])
+	})
+	list = slices.CompactFunc(list, func(a, b Expression) bool {
+		return keys[a] == keys[b]
+	})
+
+	switch len(list) {
+	case 0:
+		return nil
+	case 1:
+		return list[0]
+	}
+
+	e.List = list
+
+	return e
+}
+
+// mergeEqualities merges same field eq and in comparisons of an OR group into one IN comparison.
+//   - Only string values are merged, typed values are kept as they are.
+func mergeEqualities(list []Expression) []Expression {
+	merged := make(map[string]*ExpressionCmp)
+	result := make([]Expression, 0, len(list))
+
+	for _, sub := range list {
+		cmp, ok := sub.(*ExpressionCmp)
+		if !ok || (cmp.Operator != OperatorEq && cmp.Operator != OperatorIn) {
+			result = append(result, sub)
+
+			continue
+		}
+
+		var values []string
+		switch v := cmp.Value.(type) {
+		case string:
+			values = []string{v}
+		case []string:
+			values = v
+		default:
+			result = append(result, sub)
+
+			continue
+		}
+
+		if m, ok := merged[cmp.Field]; ok {
+			m.Value = append(m.Value.([]string), values...)
+
+			continue
+		}
+
+		m := NewExpressionCmp(OperatorIn, cmp.Field, slices.Clone(values))
+		merged[cmp.Field] = m
+		result = append(result, m)
+	}
+
+	for i, sub := range result {
+		m, ok := sub.(*ExpressionCmp)
+		if !ok || merged[m.Field] != m {
+			continue
+		}
+
+		values := m.Value.([]string)
+		slices.Sort(values)
+		values = slices.Compact(values)
+
+		if len(values) == 1 {
+			result[i] = NewExpressionCmp(OperatorEq, m.Field, values[0])
+		} else {
+			m.Value = values
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,101 @@
+package query
+
+import "testing"
+
+func TestQuery_Normalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "single element groups",
+			value: "((name=foo))",
+			want:  "name=foo",
+		},
+		{
+			name:  "flatten nested and",
+			value: "(age=1&(name=foo&(nick=bar)))",
+			want:  "age=1&name=foo&nick=bar",
+		},
+		{
+			name:  "flatten nested or",
+			value: "(age=1|(name=foo|nick=bar))",
+			want:  "(age=1|name=foo|nick=bar)",
+		},
+		{
+			name:  "merge equalities",
+			value: "name=b|name=a|nick=c",
+			want:  "(name[in]=a,b|nick=c)",
+		},
+		{
+			name:  "merge equalities with in",
+			value: "name=b|name[in]=c,a|name=a",
+			want:  "name[in]=a,b,c",
+		},
+		{
+			name:  "merge to single value",
+			value: "name=a|name=a",
+			want:  "name=a",
+		},
+		{
+			name:  "remove duplicates",
+			value: "age=1&name=foo&age=1",
+			want:  "age=1&name=foo",
+		},
+		{
+			name:  "sort in values",
+			value: "name[nin]=c,a,c",
+			want:  "name[nin]=a,c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.value)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			text, err := q.Normalize().MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.want {
+				t.Errorf("Normalize() = %s, want %s", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_NormalizeEquivalent(t *testing.T) {
+	equivalent := []string{
+		"name=a|name=b&age[gt]=1&(status=x|(status=y))",
+		"age[gt]=1&(status[in]=y,x)&(name=b|name=a)",
+		"((status=y|status=x)&name=b,a)&age[gt]=1&age[gt]=1",
+	}
+
+	var want string
+	for i, value := range equivalent {
+		q, err := Parse(value)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		text, err := q.Normalize().MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText() error = %v", err)
+		}
+
+		if i == 0 {
+			want = string(text)
+
+			continue
+		}
+
+		if string(text) != want {
+			t.Errorf("Normalize(%s) = %s, want %s", value, text, want)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package query
 
+import (
+	"cmp"
+	"strings"
+)
+
 type optionQuery struct {
 	DefaultOffset *uint64
 	DefaultLimit  *uint64
@@ -14,10 +19,52 @@ type optionQuery struct {
 	KeyOperator       map[string]operatorCmpType
 	KeyValueTransform map[string]func(string) string
 	CommaSplit        map[string]struct{}
+
+	SearchFields []string
+	NullValue    map[string]struct{}
+	Aggregation  bool
+
+	FilterKey    func(key string) (string, bool)
+	FilterPrefix string
+	SpecialKeys  SpecialKeys
+
+	Scope       []*ExpressionCmp
+	ScopeFields map[string]struct{}
+	ScopeReject bool
+
+	MaxDepth       int
+	MaxComparisons int
+	MaxOrBranches  int
+	MaxListItems   int
+	MaxQueryLength int
 }
 
 type OptionQuery func(*optionQuery)
 
+func newOptionQuery(opts ...OptionQuery) *optionQuery {
+	o := &optionQuery{
+		SkipUnderscore: true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// specialKeys returns the effective key names based on the underscore prefix and special keys options.
+func (o *optionQuery) specialKeys() (fields, sort, limit, offset string) {
+	fields, sort, limit, offset = keyFields, keySort, keyLimit, keyOffset
+	if o.UnderscorePrefix != nil && !*o.UnderscorePrefix {
+		fields, sort, limit, offset = keyFieldsNoPrefix, keySortNoPrefix, keyLimitNoPrefix, keyOffsetNoPrefix
+	}
+
+	return cmp.Or(o.SpecialKeys.Fields, fields),
+		cmp.Or(o.SpecialKeys.Sort, sort),
+		cmp.Or(o.SpecialKeys.Limit, limit),
+		cmp.Or(o.SpecialKeys.Offset, offset)
+}
+
 // WithDefaultOffset sets the default offset value.
 func WithDefaultOffset(offset uint64) OptionQuery {
 	return func(o *optionQuery) {
@@ -56,6 +103,215 @@ func WithSkipExpressionCmp(key ...string) OptionQuery {
 	}
 }
 
+// WithFilterKey sets a function to pick the params that belong to the filter.
+// The function gets the raw key with its operator and returns the filter key, false skips the param.
+// Special keys like _limit and _sort are handled before and are not passed to the function.
+// Fields of a JSON body, see ParseJSON, are not passed to the function either.
+//
+//	// filter.name[ilike]=foo -> name[ilike]=foo, other params are skipped
+//	query.WithFilterKey(func(key string) (string, bool) {
+//	    return strings.CutPrefix(key, "filter.")
+//	})
+func WithFilterKey(fn func(key string) (string, bool)) OptionQuery {
+	return func(o *optionQuery) {
+		o.FilterKey = fn
+	}
+}
+
+// resolvedKeys returns the special keys with the default names filled in.
+func (o *optionQuery) resolvedKeys() SpecialKeys {
+	fields, sort, limit, offset := o.specialKeys()
+
+	group, having, search := keyGroup, keyHaving, keySearch
+	if o.UnderscorePrefix != nil && !*o.UnderscorePrefix {
+		group, having, search = keyGroupNoPrefix, keyHavingNoPrefix, keySearchNoPrefix
+	}
+
+	return SpecialKeys{
+		Fields: fields,
+		Sort:   sort,
+		Limit:  limit,
+		Offset: offset,
+		Page:   o.SpecialKeys.Page,
+		Group:  cmp.Or(o.SpecialKeys.Group, group),
+		Having: cmp.Or(o.SpecialKeys.Having, having),
+		Search: cmp.Or(o.SpecialKeys.Search, search),
+	}
+}
+
+// hasCustomKeys reports whether the special keys differ from the defaults.
+func (o *optionQuery) hasCustomKeys() bool {
+	return o.SpecialKeys != (SpecialKeys{}) || (o.UnderscorePrefix != nil && !*o.UnderscorePrefix)
+}
+
+// WithFilterPrefix sets a namespace for the filter keys, other params are skipped.
+// Both the nested and the dotted style are accepted, for prefix "filter":
+//   - filter[name][ilike]=x -> name[ilike]=x
+//   - filter.name[ilike]=x -> name[ilike]=x
+//
+// ToValues and EncodeURL write filter keys in the nested style.
+// Use WithSpecialKeys to rename the special keys, e.g. page[size] for the limit.
+func WithFilterPrefix(prefix string) OptionQuery {
+	return func(o *optionQuery) {
+		o.FilterPrefix = prefix
+		o.FilterKey = func(key string) (string, bool) {
+			return cutFilterPrefix(key, prefix)
+		}
+	}
+}
+
+// cutFilterPrefix returns the filter key without the prefix namespace.
+func cutFilterPrefix(key, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok || len(rest) < 2 {
+		return "", false
+	}
+
+	switch rest[0] {
+	case '.':
+		return rest[1:], true
+	case '[':
+		end := strings.IndexByte(rest, ']')
+		if end <= 1 {
+			return "", false
+		}
+
+		return rest[1:end] + rest[end+1:], true
+	}
+
+	return "", false
+}
+
+// SpecialKeys holds the names of the special query keys.
+//   - Empty names keep the default name, see WithUnderscorePrefix.
+type SpecialKeys struct {
+	// Fields is the name of the field selection key, default _fields.
+	Fields string
+	// Sort is the name of the sort key, default _sort.
+	Sort string
+	// Limit is the name of the limit key, default _limit.
+	Limit string
+	// Offset is the name of the offset key, default _offset.
+	Offset string
+	// Page is the name of the page number key, empty means no page key.
+	// Pages start from 1 and are converted to the offset (page-1)*limit.
+	Page string
+	// Group is the name of the group by key, default _group, see WithAggregation.
+	Group string
+	// Having is the name of the aggregate filter key, default _having, see WithAggregation.
+	Having string
+	// Search is the name of the global search key, default _q, see WithSearchFields.
+	Search string
+}
+
+// WithSpecialKeys sets the names of the special query keys.
+// The parsed query keeps the names, so MarshalText writes them back.
+//
+//	query.WithSpecialKeys(query.SpecialKeys{Limit: "per_page", Page: "page", Sort: "order_by", Fields: "select"})
+func WithSpecialKeys(keys SpecialKeys) OptionQuery {
+	return func(o *optionQuery) {
+		o.SpecialKeys = keys
+	}
+}
+
+// WithPageKeys sets page number pagination, page is the page number key and size the page size key.
+// The page size is the limit, so the size key replaces the limit key,
+// other special keys keep their names, see WithSpecialKeys.
+//
+//	// ?page=3&page_size=20 -> offset 40, limit 20
+//	query.WithPageKeys("page", "page_size")
+func WithPageKeys(page, size string) OptionQuery {
+	return func(o *optionQuery) {
+		o.SpecialKeys.Page = page
+		o.SpecialKeys.Limit = size
+	}
+}
+
+// WithSearchFields enables the global search key, _q=foo searches the text in every field.
+//   - The search is added to the where clause as search comparisons combined with OR.
+//   - The key is a normal filter key when no search fields are set.
+//
+// The search of _q=foo in the title and the body:
+//
+//	// _q=foo -> (title[search]=foo|body[search]=foo)
+//	query.WithSearchFields("title", "body")
+func WithSearchFields(fields ...string) OptionQuery {
+	return func(o *optionQuery) {
+		o.SearchFields = fields
+	}
+}
+
+// searchExpression returns the search of the text in the fields.
+func searchExpression(fields []string, text string) Expression {
+	if len(fields) == 1 {
+		return NewExpressionCmp(OperatorSearch, fields[0], text)
+	}
+
+	list := make([]Expression, 0, len(fields))
+	for _, field := range fields {
+		list = append(list, NewExpressionCmp(OperatorSearch, field, text))
+	}
+
+	return NewExpressionLogic(OperatorOr, list)
+}
+
+// WithNullValue sets the values parsed as SQL NULL, like null or \0.
+//   - name=null -> name[is]=, name[ne]=null -> name[not]=
+//   - isdistinct and notdistinct compare with NULL, other operators keep the value.
+func WithNullValue(values ...string) OptionQuery {
+	return func(o *optionQuery) {
+		if o.NullValue == nil {
+			o.NullValue = make(map[string]struct{}, len(values))
+		}
+
+		for _, v := range values {
+			o.NullValue[v] = struct{}{}
+		}
+	}
+}
+
+// WithScope sets mandatory comparisons that the user query cannot override.
+//   - Every user clause on a scoped field is removed, anywhere in nested AND/OR groups.
+//   - The scope comparisons are added with AND at the root of the query.
+//   - Use WithScopeReject to return an error instead of removing the user clauses.
+//
+// Unlike WithExpressionCmp, a crafted "(tenant_id=1|tenant_id=2)" can never widen the scope.
+func WithScope(cmps ...*ExpressionCmp) OptionQuery {
+	return func(o *optionQuery) {
+		if o.ScopeFields == nil {
+			o.ScopeFields = make(map[string]struct{})
+		}
+
+		for _, cmp := range cmps {
+			if cmp == nil {
+				continue
+			}
+
+			o.Scope = append(o.Scope, cmp)
+			o.ScopeFields[cmp.Field] = struct{}{}
+		}
+	}
+}
+
+// WithScopeReject sets whether a user clause on a scoped field returns ErrScopeViolation.
+//   - Default is false, the clause is removed.
+func WithScopeReject(v bool) OptionQuery {
+	return func(o *optionQuery) {
+		o.ScopeReject = v
+	}
+}
+
+// WithAggregation sets whether the group by and having keys and the aggregates are parsed.
+//   - Default is false, _group and _having are normal keys and skipped with the underscore prefix.
+//   - The JSON body fields "group" and "having" are ignored when disabled.
+//   - Aggregates like count(*) in the fields or the sort are an error when disabled.
+//   - Filters on aggregates are always an error, use the having key.
+func WithAggregation(v bool) OptionQuery {
+	return func(o *optionQuery) {
+		o.Aggregation = v
+	}
+}
+
 // WithSkipUnderscore sets whether to skip keys starting with underscore.
 //   - Default is true.
 func WithSkipUnderscore(v bool) OptionQuery {
@@ -131,6 +387,51 @@ func WithCommaSplit(keys ...string) OptionQuery {
 	}
 }
 
+// WithMaxDepth sets the maximum nesting depth of the groups of the where tree, like WithDepth of the validator.
+//   - OR groups and nested parentheses are groups, the outer parentheses of a pair are not: a=1|b=2 and ((a=1)) have depth 1.
+//   - Parsing stops with a *LimitError as soon as the depth is exceeded.
+//   - 0 means no limit.
+func WithMaxDepth(n int) OptionQuery {
+	return func(o *optionQuery) {
+		o.MaxDepth = n
+	}
+}
+
+// WithMaxComparisons sets the maximum number of comparisons in the query.
+//   - Parsing stops with a *LimitError as soon as the count is exceeded.
+//   - 0 means no limit.
+func WithMaxComparisons(n int) OptionQuery {
+	return func(o *optionQuery) {
+		o.MaxComparisons = n
+	}
+}
+
+// WithMaxOrBranches sets the maximum number of OR branches in the whole query.
+//   - "a=1|b=2&(c=3|d=4|e=5)" has 5 OR branches.
+//   - 0 means no limit.
+func WithMaxOrBranches(n int) OptionQuery {
+	return func(o *optionQuery) {
+		o.MaxOrBranches = n
+	}
+}
+
+// WithMaxListItems sets the maximum number of values in a single comparison, like 'in' and 'jin' lists.
+//   - 0 means no limit.
+func WithMaxListItems(n int) OptionQuery {
+	return func(o *optionQuery) {
+		o.MaxListItems = n
+	}
+}
+
+// WithMaxQueryLength sets the maximum length of the raw query string in bytes.
+//   - Checked before anything else is parsed.
+//   - 0 means no limit.
+func WithMaxQueryLength(n int) OptionQuery {
+	return func(o *optionQuery) {
+		o.MaxQueryLength = n
+	}
+}
+
 // KeyOption is a functional option scoped to a single key, used with WithKey.
 type KeyOption func(key string, o *optionQuery)
 
@@ -0,0 +1,140 @@
+package query
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PageInfo holds the pagination metadata of a query, see Pagination.
+type PageInfo struct {
+	// Page is the current page number, starting from 1.
+	Page uint64 `json:"page"`
+	// PageSize is the limit of the query, 0 means no limit.
+	PageSize uint64 `json:"page_size"`
+	// Total is the total number of records matching the query.
+	Total uint64 `json:"total"`
+	// TotalPages is the number of pages, 0 when there is no record.
+	TotalPages uint64 `json:"total_pages"`
+
+	// First, Prev, Next and Last are copies of the query with the offset of the page.
+	//   - Prev and Next are nil when there is no such page.
+	//   - First and Last are nil when the query has no limit.
+	First *Query `json:"-"`
+	Prev  *Query `json:"-"`
+	Next  *Query `json:"-"`
+	Last  *Query `json:"-"`
+}
+
+// Pagination returns the pagination metadata of the query for the total number of records.
+//   - The query is not changed, the page queries are clones with a new offset.
+//   - An offset that is not at a page boundary counts as the page containing it,
+//     the previous page starts one page size before the offset.
+//   - A query without a limit is a single page.
+func Pagination(q *Query, total uint64) PageInfo {
+	info := PageInfo{
+		Page:     1,
+		PageSize: q.GetLimit(),
+		Total:    total,
+	}
+
+	if info.PageSize == 0 {
+		if total > 0 {
+			info.TotalPages = 1
+		}
+
+		return info
+	}
+
+	offset := q.GetOffset()
+	info.Page = offset/info.PageSize + 1
+	info.TotalPages = (total + info.PageSize - 1) / info.PageSize
+
+	info.First = pageQuery(q, 0)
+	if info.TotalPages > 0 {
+		info.Last = pageQuery(q, (info.TotalPages-1)*info.PageSize)
+	} else {
+		info.Last = pageQuery(q, 0)
+	}
+
+	if offset > 0 {
+		info.Prev = pageQuery(q, offset-min(offset, info.PageSize))
+	}
+
+	if offset+info.PageSize < total {
+		info.Next = pageQuery(q, offset+info.PageSize)
+	}
+
+	return info
+}
+
+// pageQuery returns a copy of the query with the offset.
+func pageQuery(q *Query, offset uint64) *Query {
+	c := q.Clone()
+	c.Offset = &offset
+
+	return c
+}
+
+// FirstURL returns the URL of the first page, empty when there is no such page.
+//   - The query is encoded into a copy of u with EncodeURL and the options.
+func (p PageInfo) FirstURL(u *url.URL, opts ...OptionQuery) string {
+	return pageURL(p.First, u, opts)
+}
+
+// PrevURL returns the URL of the previous page, empty when there is no such page.
+func (p PageInfo) PrevURL(u *url.URL, opts ...OptionQuery) string {
+	return pageURL(p.Prev, u, opts)
+}
+
+// NextURL returns the URL of the next page, empty when there is no such page.
+func (p PageInfo) NextURL(u *url.URL, opts ...OptionQuery) string {
+	return pageURL(p.Next, u, opts)
+}
+
+// LastURL returns the URL of the last page, empty when there is no such page.
+func (p PageInfo) LastURL(u *url.URL, opts ...OptionQuery) string {
+	return pageURL(p.Last, u, opts)
+}
+
+// LinkHeader returns the value of the Link header (RFC 8288) with the first, prev, next and last pages.
+//   - Pages that do not exist are left out, empty when there is no page link.
+func (p PageInfo) LinkHeader(u *url.URL, opts ...OptionQuery) string {
+	links := make([]string, 0, 4)
+	for _, l := range []struct {
+		rel string
+		q   *Query
+	}{
+		{"first", p.First},
+		{"prev", p.Prev},
+		{"next", p.Next},
+		{"last", p.Last},
+	} {
+		if v := pageURL(l.q, u, opts); v != "" {
+			links = append(links, `<`+v+`>; rel="`+l.rel+`"`)
+		}
+	}
+
+	return strings.Join(links, ", ")
+}
+
+func pageURL(q *Query, u *url.URL, opts []OptionQuery) string {
+	if q == nil || u == nil {
+		return ""
+	}
+
+	keys := q.specialKeys()
+	if o := newOptionQuery(opts...); o.hasCustomKeys() {
+		keys = o.resolvedKeys()
+	}
+
+	// The page is set with either the page or the offset key, drop both from the current URL.
+	c := *u
+	values := c.Query()
+	values.Del(keys.Page)
+	values.Del(keys.Offset)
+	c.RawQuery = values.Encode()
+
+	q.EncodeURL(&c, opts...)
+
+	return c.String()
+}
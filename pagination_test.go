@@ -0,0 +1,145 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     []OptionQuery
+		total    uint64
+		page     uint64
+		pages    uint64
+		prev     string
+		next     string
+		last     string
+		hasFirst bool
+	}{
+		{
+			name:     "first page",
+			query:    "name=foo&_limit=10",
+			total:    25,
+			page:     1,
+			pages:    3,
+			next:     "/users?_limit=10&_offset=10&name=foo",
+			last:     "/users?_limit=10&_offset=20&name=foo",
+			hasFirst: true,
+		},
+		{
+			name:     "middle page",
+			query:    "name=foo&_limit=10&_offset=10",
+			total:    25,
+			page:     2,
+			pages:    3,
+			prev:     "/users?_limit=10&_offset=0&name=foo",
+			next:     "/users?_limit=10&_offset=20&name=foo",
+			last:     "/users?_limit=10&_offset=20&name=foo",
+			hasFirst: true,
+		},
+		{
+			name:     "last page",
+			query:    "_limit=10&_offset=20",
+			total:    25,
+			page:     3,
+			pages:    3,
+			prev:     "/users?_limit=10&_offset=10",
+			last:     "/users?_limit=10&_offset=20",
+			hasFirst: true,
+		},
+		{
+			name:     "offset not at page boundary",
+			query:    "_limit=10&_offset=5",
+			total:    25,
+			page:     1,
+			pages:    3,
+			prev:     "/users?_limit=10&_offset=0",
+			next:     "/users?_limit=10&_offset=15",
+			last:     "/users?_limit=10&_offset=20",
+			hasFirst: true,
+		},
+		{
+			name:     "page keys",
+			query:    "page=2&page_size=10",
+			opts:     []OptionQuery{WithPageKeys("page", "page_size")},
+			total:    30,
+			page:     2,
+			pages:    3,
+			prev:     "/users?page=1&page_size=10",
+			next:     "/users?page=3&page_size=10",
+			last:     "/users?page=3&page_size=10",
+			hasFirst: true,
+		},
+		{
+			name:     "no records",
+			query:    "_limit=10",
+			total:    0,
+			page:     1,
+			pages:    0,
+			last:     "/users?_limit=10&_offset=0",
+			hasFirst: true,
+		},
+		{
+			name:  "no limit",
+			query: "name=foo",
+			total: 25,
+			page:  1,
+			pages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			offset := q.GetOffset()
+			u := &url.URL{Path: "/users", RawQuery: tt.query}
+
+			p := Pagination(q, tt.total)
+			if p.Page != tt.page || p.TotalPages != tt.pages || p.Total != tt.total {
+				t.Fatalf("Pagination() = page %d of %d, total %d, want page %d of %d", p.Page, p.TotalPages, p.Total, tt.page, tt.pages)
+			}
+
+			if got := p.PrevURL(u); got != tt.prev {
+				t.Errorf("PrevURL() = %q, want %q", got, tt.prev)
+			}
+			if got := p.NextURL(u); got != tt.next {
+				t.Errorf("NextURL() = %q, want %q", got, tt.next)
+			}
+			if got := p.LastURL(u); got != tt.last {
+				t.Errorf("LastURL() = %q, want %q", got, tt.last)
+			}
+			if (p.First != nil) != tt.hasFirst {
+				t.Errorf("First = %v, want %v", p.First, tt.hasFirst)
+			}
+
+			if q.GetOffset() != offset {
+				t.Errorf("Pagination() changed the query offset to %d", q.GetOffset())
+			}
+		})
+	}
+}
+
+func TestPageInfo_LinkHeader(t *testing.T) {
+	q, err := Parse("_limit=10&_offset=10")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	u, _ := url.Parse("https://api.example.com/users?_limit=10&_offset=10")
+
+	got := Pagination(q, 30).LinkHeader(u)
+	want := `<https://api.example.com/users?_limit=10&_offset=0>; rel="first", ` +
+		`<https://api.example.com/users?_limit=10&_offset=0>; rel="prev", ` +
+		`<https://api.example.com/users?_limit=10&_offset=20>; rel="next", ` +
+		`<https://api.example.com/users?_limit=10&_offset=20>; rel="last"`
+
+	if got != want {
+		t.Fatalf("LinkHeader() = %s, want %s", got, want)
+	}
+}
@@ -1,7 +1,9 @@
 package query
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"strconv"
 	"strings"
@@ -12,13 +14,22 @@ const (
 	keySort   = "_sort"
 	keyLimit  = "_limit"
 	keyOffset = "_offset"
+	keyGroup  = "_group"
+	keyHaving = "_having"
+	keySearch = "_q"
 
 	keyFieldsNoPrefix = "fields"
 	keySortNoPrefix   = "sort"
 	keyLimitNoPrefix  = "limit"
 	keyOffsetNoPrefix = "offset"
+	keyGroupNoPrefix  = "group"
+	keyHavingNoPrefix = "having"
+	keySearchNoPrefix = "q"
 )
 
+// ErrScopeViolation is returned when a query touches a field of the forced scope with WithScopeReject.
+var ErrScopeViolation = errors.New("scoped field is not allowed")
+
 func ParseWithValidator(query string, validator *Validator, opts ...OptionQuery) (*Query, error) {
 	q, err := Parse(query, opts...)
 	if err != nil {
@@ -38,119 +49,222 @@ func ParseWithValidator(query string, validator *Validator, opts ...OptionQuery)
 
 // Parse parses a query string into a Query struct.
 func Parse(query string, opts ...OptionQuery) (*Query, error) {
-	o := &optionQuery{
-		SkipUnderscore: true,
-	}
-	for _, opt := range opts {
-		opt(o)
+	p := newParser(newOptionQuery(opts...))
+	result := New()
+
+	if err := p.parseRaw(result, query); err != nil {
+		return nil, err
 	}
 
-	// Determine the effective key names based on the underscore prefix option.
-	kFields, kSort, kLimit, kOffset := keyFields, keySort, keyLimit, keyOffset
-	if o.UnderscorePrefix != nil && !*o.UnderscorePrefix {
-		kFields = keyFieldsNoPrefix
-		kSort = keySortNoPrefix
-		kLimit = keyLimitNoPrefix
-		kOffset = keyOffsetNoPrefix
+	if err := p.finish(result); err != nil {
+		return nil, err
 	}
 
-	result := New()
+	return result, nil
+}
+
+// parseRaw parses a raw, URL encoded, query string into result.
+func (p *parser) parseRaw(result *Query, query string) error {
+	if p.o.MaxQueryLength > 0 && len(query) > p.o.MaxQueryLength {
+		return &LimitError{Limit: LimitQueryLength, Max: p.o.MaxQueryLength, Value: len(query)}
+	}
 
 	// Fast path: skip unescape when the query contains no percent-encoded or plus-encoded chars.
 	if strings.ContainsAny(query, "%+") {
 		var err error
 		query, err = url.QueryUnescape(query)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// Split the query by & to get key-value pairs
 	for _, pair := range split(query, '&') {
-		// Handle each parameter
-		if pair == "" {
-			continue
+		if err := p.parsePair(result, pair); err != nil {
+			return err
 		}
+	}
 
-		if isParenthesesAny(pair) {
-			// Handle standalone parentheses expression
-			exprs, err := parseFilter(pair, o.KeyType, o.KeyOperator, o.KeyValueTransform, o.CommaSplit)
-			if err != nil {
-				return nil, err
+	return nil
+}
+
+// parsePair parses a single decoded key-value pair, or a standalone parentheses expression, into result.
+func (p *parser) parsePair(result *Query, pair string) error {
+	if pair == "" {
+		return nil
+	}
+
+	key, value, _ := strings.Cut(pair, "=")
+
+	// Special keys are checked first, their values may contain parentheses like count(*).
+	if ok, err := p.parseSpecial(result, key, value); ok {
+		return err
+	}
+
+	if isParenthesesAny(pair) {
+		// Handle standalone parentheses expression
+		exprs, err := p.parseFilter(pair, 0)
+		if err != nil {
+			return err
+		}
+
+		var expr Expression
+		switch length := len(exprs); {
+		case length > 1:
+			expr = &ExpressionLogic{
+				Operator: OperatorAnd,
+				List:     exprs,
 			}
+		case length == 1:
+			expr = exprs[0]
+		default:
+			return nil
+		}
 
-			var expr Expression
-			switch length := len(exprs); {
-			case length > 1:
-				expr = &ExpressionLogic{
-					Operator: OperatorAnd,
-					List:     exprs,
-				}
-			case length == 1:
-				expr = exprs[0]
-			default:
-				continue
+		return p.addWhere(result, expr)
+	}
+
+	// Handle filtering
+	expr, err := p.parseFilterExpr(key, value)
+	if err != nil {
+		return err
+	}
+
+	return p.addWhere(result, expr)
+}
+
+// parseSpecial parses the special keys like _limit and _sort, false when the key is not a special key.
+func (p *parser) parseSpecial(result *Query, key, value string) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+
+	switch key {
+	case p.keys.Page:
+		// Handle page, converted to offset when all pairs are parsed
+		if value == "" {
+			return true, nil
+		}
+		page, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || page == 0 {
+			return true, fmt.Errorf("invalid page value: %s", value)
+		}
+		p.page = &page
+	case p.keys.Fields:
+		// Handle field selection
+		if value == "" {
+			return true, nil
+		}
+
+		for field := range strings.SplitSeq(value, ",") {
+			if field != "" {
+				result.Select = append(result.Select, field)
 			}
+		}
+	case p.keys.Group:
+		// Handle group by, only when aggregations are enabled
+		if !p.o.Aggregation {
+			return false, nil
+		}
 
-			processed := resultAddExpr(result, expr, o)
-			if processed != nil {
-				result.Where = append(result.Where, processed)
+		if value == "" {
+			return true, nil
+		}
+
+		for field := range strings.SplitSeq(value, ",") {
+			if field != "" {
+				result.Group = append(result.Group, field)
 			}
+		}
+	case p.keys.Having:
+		// Handle filters on aggregates, only when aggregations are enabled
+		if !p.o.Aggregation {
+			return false, nil
+		}
 
-			continue
+		if value == "" {
+			return true, nil
 		}
 
-		key, value, _ := strings.Cut(pair, "=")
+		p.having = true
+		exprs, err := p.parseFilter(value, 0)
+		p.having = false
+		if err != nil {
+			return true, err
+		}
 
-		switch key {
-		case kFields:
-			// Handle field selection
-			if value == "" {
-				continue
-			}
+		result.Having = append(result.Having, exprs...)
+	case p.keys.Search:
+		// Handle the global search, a search filter on every search field
+		if len(p.o.SearchFields) == 0 {
+			return false, nil
+		}
 
-			for field := range strings.SplitSeq(value, ",") {
-				if field != "" {
-					result.Select = append(result.Select, field)
-				}
-			}
-		case kSort:
-			// Handle sorting
-			if value == "" {
-				continue
-			}
-			result.Sort = parseSort(value)
-		case kLimit:
-			// Handle limit
-			if value == "" {
-				continue
-			}
-			limit, err := strconv.ParseUint(value, 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid limit value: %s", value)
-			}
-			result.Limit = &limit
-		case kOffset:
-			// Handle offset
-			if value == "" {
-				continue
-			}
-			offset, err := strconv.ParseUint(value, 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid offset value: %s", value)
-			}
-			result.Offset = &offset
-		default:
-			// Handle filtering
-			expr, err := parseFilterExpr(key, value, o.KeyType, o.KeyOperator, o.KeyValueTransform, o.CommaSplit)
-			if err != nil {
-				return nil, err
-			}
+		if strings.TrimSpace(value) == "" {
+			return true, nil
+		}
 
-			processed := resultAddExpr(result, expr, o)
-			if processed != nil {
-				result.Where = append(result.Where, processed)
-			}
+		if err := p.addWhere(result, searchExpression(p.o.SearchFields, value)); err != nil {
+			return true, err
+		}
+	case p.keys.Sort:
+		// Handle sorting
+		if value == "" {
+			return true, nil
+		}
+		result.Sort = parseSort(value)
+	case p.keys.Limit:
+		// Handle limit
+		if value == "" {
+			return true, nil
+		}
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return true, fmt.Errorf("invalid limit value: %s", value)
+		}
+		result.Limit = &limit
+	case p.keys.Offset:
+		// Handle offset
+		if value == "" {
+			return true, nil
+		}
+		offset, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return true, fmt.Errorf("invalid offset value: %s", value)
+		}
+		result.Offset = &offset
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// addWhere adds a parsed root expression to result, applying skip and scope options.
+func (p *parser) addWhere(result *Query, expr Expression) error {
+	if err := p.checkDepth(expressionDepth(expr)); err != nil {
+		return err
+	}
+
+	processed, err := resultAddExpr(result, expr, p.o)
+	if err != nil {
+		return err
+	}
+
+	if processed != nil {
+		result.Where = append(result.Where, processed)
+	}
+
+	return nil
+}
+
+// finish applies defaults, the page, fixed expressions and the forced scope after all pairs are parsed.
+func (p *parser) finish(result *Query) error {
+	o := p.o
+
+	if !o.Aggregation {
+		if err := checkNoAggregates(result); err != nil {
+			return err
 		}
 	}
 
@@ -161,6 +275,24 @@ func Parse(query string, opts ...OptionQuery) (*Query, error) {
 		result.Limit = o.DefaultLimit
 	}
 
+	if p.page != nil {
+		if result.Limit == nil || *result.Limit == 0 {
+			return fmt.Errorf("page [%d] requires a limit", *p.page)
+		}
+
+		if *p.page-1 > math.MaxUint64 / *result.Limit {
+			return fmt.Errorf("page [%d] with limit [%d] overflows the offset", *p.page, *result.Limit)
+		}
+
+		offset := (*p.page - 1) * *result.Limit
+		result.Offset = &offset
+	}
+
+	if o.hasCustomKeys() {
+		keys := o.resolvedKeys()
+		result.keys = &keys
+	}
+
 	for key, value := range o.Value {
 		if result.Values == nil {
 			result.Values = make(map[string][]*ExpressionCmp)
@@ -170,15 +302,29 @@ func Parse(query string, opts ...OptionQuery) (*Query, error) {
 		result.Where = append(result.Where, value)
 	}
 
-	return result, nil
+	// Forced scope is added at the root, user clauses on the scoped fields are already removed.
+	// The scope is copied, options are shared between parse calls.
+	for _, cmp := range o.Scope {
+		result.AddWhere(cloneCmp(cmp, map[*ExpressionCmp]*ExpressionCmp{}))
+	}
+
+	return nil
 }
 
-func resultAddExpr(result *Query, expr Expression, o *optionQuery) Expression {
+func resultAddExpr(result *Query, expr Expression, o *optionQuery) (Expression, error) {
 	if expr == nil {
-		return nil
+		return nil, nil
 	}
 
 	if cmp, ok := expr.(*ExpressionCmp); ok {
+		if _, ok := o.ScopeFields[cmp.Field]; ok {
+			if o.ScopeReject {
+				return nil, fmt.Errorf("%w: field [%s]", ErrScopeViolation, cmp.Field)
+			}
+
+			return nil, nil
+		}
+
 		if result.Values == nil {
 			result.Values = make(map[string][]*ExpressionCmp)
 		}
@@ -186,21 +332,25 @@ func resultAddExpr(result *Query, expr Expression, o *optionQuery) Expression {
 		result.Values[cmp.Field] = append(result.Values[cmp.Field], cmp)
 
 		if o.SkipUnderscore && strings.HasPrefix(cmp.Field, "_") {
-			return nil
+			return nil, nil
 		}
 
 		if _, ok := o.Skip[cmp.Field]; ok {
-			return nil
+			return nil, nil
 		}
 
-		return cmp
+		return cmp, nil
 	}
 
 	if exprLogic, ok := expr.(*ExpressionLogic); ok {
 		// Filter in-place: reuse the existing slice to avoid allocating a new one.
 		n := 0
 		for _, e := range exprLogic.List {
-			processed := resultAddExpr(result, e, o)
+			processed, err := resultAddExpr(result, e, o)
+			if err != nil {
+				return nil, err
+			}
+
 			if processed != nil {
 				exprLogic.List[n] = processed
 				n++
@@ -214,14 +364,14 @@ func resultAddExpr(result *Query, expr Expression, o *optionQuery) Expression {
 		exprLogic.List = exprLogic.List[:n]
 
 		if n == 0 {
-			return nil
+			return nil, nil
 		}
 
-		return exprLogic
+		return exprLogic, nil
 	}
 
 	// Other expression types
-	return expr
+	return expr, nil
 }
 
 // parseSort parses the sort parameter and returns the ordered expressions.
@@ -269,9 +419,65 @@ func parseSort(value string) []ExpressionSort {
 	return orderedExpressions
 }
 
-func parseFilter(value string, keyType map[string]ValueType, keyOperator map[string]operatorCmpType, keyValueTransform map[string]func(string) string, commaSplit map[string]struct{}) ([]Expression, error) {
+// parser holds the options and the running counters of a single parse call.
+type parser struct {
+	o    *optionQuery
+	keys SpecialKeys
+
+	comparisons int
+	orBranches  int
+
+	page *uint64
+	// having is set while the having clauses are parsed.
+	having bool
+	// json is set while a JSON body is parsed, its field names are not filter keys.
+	json bool
+}
+
+func newParser(o *optionQuery) *parser {
+	return &parser{o: o, keys: o.resolvedKeys()}
+}
+
+// addComparison counts a parsed comparison against the configured limits.
+func (p *parser) addComparison(cmp *ExpressionCmp) error {
+	p.comparisons++
+	if p.o.MaxComparisons > 0 && p.comparisons > p.o.MaxComparisons {
+		return &LimitError{Limit: LimitComparisons, Max: p.o.MaxComparisons, Value: p.comparisons}
+	}
+
+	if p.o.MaxListItems > 0 {
+		if l := len(cmpValues(cmp)); l > p.o.MaxListItems {
+			return &LimitError{Limit: LimitListItems, Max: p.o.MaxListItems, Value: l}
+		}
+	}
+
+	return nil
+}
+
+// checkDepth checks the depth of a group against the configured limit.
+func (p *parser) checkDepth(depth int) error {
+	if p.o.MaxDepth > 0 && depth > p.o.MaxDepth {
+		return &LimitError{Limit: LimitDepth, Max: p.o.MaxDepth, Value: depth}
+	}
+
+	return nil
+}
+
+// addOrBranches counts OR branches against the configured limit.
+func (p *parser) addOrBranches(n int) error {
+	p.orBranches += n
+	if p.o.MaxOrBranches > 0 && p.orBranches > p.o.MaxOrBranches {
+		return &LimitError{Limit: LimitOrBranches, Max: p.o.MaxOrBranches, Value: p.orBranches}
+	}
+
+	return nil
+}
+
+// parseFilter parses a filter, depth is the number of groups around the result.
+//   - The depth is checked when a group is opened, the exact depth of the tree is checked by addWhere.
+func (p *parser) parseFilter(value string, depth int) ([]Expression, error) {
 	if isParentheses(value) {
-		// Strip surrounding parentheses
+		// Strip surrounding parentheses, the group is created by the caller.
 		value = value[1 : len(value)-1]
 	}
 
@@ -286,7 +492,11 @@ func parseFilter(value string, keyType map[string]ValueType, keyOperator map[str
 
 		if isParentheses(part) {
 			// Nested parentheses
-			nestedExpr, err := parseFilter(part, keyType, keyOperator, keyValueTransform, commaSplit)
+			if err := p.checkDepth(depth + 1); err != nil {
+				return nil, err
+			}
+
+			nestedExpr, err := p.parseFilter(part, depth+1)
 			if err != nil {
 				return nil, err
 			}
@@ -299,9 +509,17 @@ func parseFilter(value string, keyType map[string]ValueType, keyOperator map[str
 		}
 
 		if parts := split(part, '|'); len(parts) > 1 {
+			if err := p.addOrBranches(len(parts)); err != nil {
+				return nil, err
+			}
+
+			if err := p.checkDepth(depth + 1); err != nil {
+				return nil, err
+			}
+
 			exsInternal := make([]Expression, 0, len(parts))
-			for _, p := range parts {
-				nestedExpr, err := parseFilter(p, keyType, keyOperator, keyValueTransform, commaSplit)
+			for _, orPart := range parts {
+				nestedExpr, err := p.parseFilter(orPart, depth+1)
 				if err != nil {
 					return nil, err
 				}
@@ -329,58 +547,155 @@ func parseFilter(value string, keyType map[string]ValueType, keyOperator map[str
 
 		partKey, partVal, _ := strings.Cut(part, "=")
 
-		exp, err := parseFilterExpr(partKey, partVal, keyType, keyOperator, keyValueTransform, commaSplit)
+		exp, err := p.parseFilterExpr(partKey, partVal)
 		if err != nil {
 			return nil, err
 		}
 
-		exs = append(exs, exp)
+		if exp != nil {
+			exs = append(exs, exp)
+		}
 	}
 
 	return exs, nil
 }
 
+// checkNoAggregates returns an error for aggregates in the selection or the sort, see WithAggregation.
+func checkNoAggregates(q *Query) error {
+	for _, field := range q.Select {
+		if IsAggregate(field) {
+			return fmt.Errorf("aggregate field [%s] needs WithAggregation", field)
+		}
+	}
+
+	for _, s := range q.Sort {
+		if IsAggregate(s.Field) {
+			return fmt.Errorf("aggregate sort [%s] needs WithAggregation", s.Field)
+		}
+	}
+
+	return nil
+}
+
+// parseExpression parses a single comparison with the parser options and counts it.
+//   - Returns nil without error when the key is not a filter key, see WithFilterKey.
+//   - Fields of a JSON body and having clauses are not filter keys and are used as is.
+func (p *parser) parseExpression(key, value string) (*ExpressionCmp, error) {
+	if p.o.FilterKey != nil && !p.having && !p.json {
+		var ok bool
+		if key, ok = p.o.FilterKey(key); !ok {
+			return nil, nil
+		}
+	}
+
+	exp, ok := p.nullExpression(key, value)
+	if !ok {
+		var err error
+		exp, err = parseExpression(key, value, p.o.KeyType[getKey(key)], p.o.KeyOperator, p.o.KeyValueTransform, p.o.CommaSplit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.having && !IsAggregate(exp.Field) {
+		return nil, fmt.Errorf("having field [%s] is not an aggregate", exp.Field)
+	}
+
+	if !p.having && IsAggregate(exp.Field) {
+		return nil, fmt.Errorf("filter field [%s] is an aggregate, aggregates are only usable in having", exp.Field)
+	}
+
+	if err := p.addComparison(exp); err != nil {
+		return nil, err
+	}
+
+	return exp, nil
+}
+
+// nullExpression returns the comparison with NULL of a null literal value, see WithNullValue.
+//   - eq becomes is and ne becomes not, name=null -> name[is]=
+//   - is, not, isdistinct and notdistinct compare with NULL.
+func (p *parser) nullExpression(key, value string) (*ExpressionCmp, bool) {
+	if _, ok := p.o.NullValue[value]; !ok {
+		return nil, false
+	}
+
+	field, operator, hasOperator := parseFieldWithOperator(key)
+	op := operatorCmpType(operator)
+	if !hasOperator {
+		op = OperatorEq
+		if keyOp, ok := p.o.KeyOperator[field]; ok {
+			op = keyOp
+		}
+	}
+
+	switch op {
+	case OperatorEq:
+		return NewExpressionCmp(OperatorIs, field, nil), true
+	case OperatorNe:
+		return NewExpressionCmp(OperatorIsNot, field, nil), true
+	case OperatorIs, OperatorIsNot, OperatorIsDistinct, OperatorNotDistinct:
+		return NewExpressionCmp(op, field, nil), true
+	}
+
+	return nil, false
+}
+
 // parseFilterExpr parses filter expressions from key-value pairs.
-func parseFilterExpr(key, value string, keyType map[string]ValueType, keyOperator map[string]operatorCmpType, keyValueTransform map[string]func(string) string, commaSplit map[string]struct{}) (Expression, error) {
+func (p *parser) parseFilterExpr(key, value string) (Expression, error) {
 	switch {
 	case strings.Contains(value, "|"):
 		// Handle OR conditions
 		parts := strings.Split(value, "|")
+		if err := p.addOrBranches(len(parts)); err != nil {
+			return nil, err
+		}
+
 		exs := make([]Expression, 0, len(parts))
 
-		exp, err := parseExpression(key, parts[0], keyType[getKey(key)], keyOperator, keyValueTransform, commaSplit)
+		exp, err := p.parseExpression(key, parts[0])
 		if err != nil {
 			return nil, err
 		}
 
-		exs = append(exs, exp)
+		if exp != nil {
+			exs = append(exs, exp)
+		}
 
 		for _, part := range parts[1:] {
 			if pKey, pVal, ok := strings.Cut(part, "="); ok {
 				// Different field
-				exp, err := parseExpression(pKey, pVal, keyType[getKey(pKey)], keyOperator, keyValueTransform, commaSplit)
-				if err != nil {
-					return nil, err
-				}
-
-				exs = append(exs, exp)
+				exp, err = p.parseExpression(pKey, pVal)
 			} else {
 				// Same field
-				exp, err := parseExpression(key, part, keyType[getKey(key)], keyOperator, keyValueTransform, commaSplit)
-				if err != nil {
-					return nil, err
-				}
+				exp, err = p.parseExpression(key, part)
+			}
+
+			if err != nil {
+				return nil, err
+			}
 
+			if exp != nil {
 				exs = append(exs, exp)
 			}
 		}
 
+		if len(exs) == 0 {
+			return nil, nil
+		}
+
 		return &ExpressionLogic{
 			Operator: OperatorOr,
 			List:     exs,
 		}, nil
 	default:
-		return parseExpression(key, value, keyType[getKey(key)], keyOperator, keyValueTransform, commaSplit)
+		exp, err := p.parseExpression(key, value)
+		if exp == nil {
+			// Avoid a non-nil interface holding a nil pointer.
+			return nil, err
+		}
+
+		return exp, nil
 	}
 }
 
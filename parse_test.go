@@ -1,6 +1,7 @@
 package query
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -994,3 +995,428 @@ func TestParseWithCommaSplit(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWithScope(t *testing.T) {
+	scope := WithScope(
+		NewExpressionCmp(OperatorEq, "tenant_id", "1"),
+		NewExpressionCmp(OperatorIs, "deleted_at", nil),
+	)
+
+	tests := []struct {
+		name      string
+		value     string
+		opts      []OptionQuery
+		want      string
+		wantErr   bool
+		wantValue []string
+	}{
+		{
+			name:      "add scope",
+			value:     "name=foo",
+			opts:      []OptionQuery{scope},
+			want:      "name=foo&tenant_id=1&deleted_at[is]=",
+			wantValue: []string{"1"},
+		},
+		{
+			name:      "strip user clause",
+			value:     "tenant_id=2&name=foo",
+			opts:      []OptionQuery{scope},
+			want:      "name=foo&tenant_id=1&deleted_at[is]=",
+			wantValue: []string{"1"},
+		},
+		{
+			name:      "strip nested or",
+			value:     "(tenant_id=1|tenant_id=2)&(name=foo|(deleted_at[not]=&tenant_id=3))",
+			opts:      []OptionQuery{scope},
+			want:      "(name=foo)&tenant_id=1&deleted_at[is]=",
+			wantValue: []string{"1"},
+		},
+		{
+			name:    "reject user clause",
+			value:   "name=foo|tenant_id=2",
+			opts:    []OptionQuery{scope, WithScopeReject(true)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.value, tt.opts...)
+			if tt.wantErr {
+				if !errors.Is(err, ErrScopeViolation) {
+					t.Fatalf("Parse() error = %v, want ErrScopeViolation", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			text, err := q.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.want {
+				t.Errorf("Parse() = %s, want %s", text, tt.want)
+			}
+
+			if got := q.GetValues("tenant_id"); !reflect.DeepEqual(got, tt.wantValue) {
+				t.Errorf("Parse() tenant_id values = %v, want %v", got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestParseWithFilterPrefix(t *testing.T) {
+	opts := []OptionQuery{
+		WithFilterPrefix("filter"),
+		WithSpecialKeys(SpecialKeys{Limit: "page[size]", Offset: "page[offset]", Sort: "sort", Fields: "fields[users]"}),
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "nested style",
+			value: "filter[name][ilike]=x&filter[age]=1,2&format=json",
+			want:  "name[ilike]=x&age[in]=1,2",
+		},
+		{
+			name:  "dotted style",
+			value: "filter.name[ilike]=x&include=roles&name=skipped",
+			want:  "name[ilike]=x",
+		},
+		{
+			name:  "groups",
+			value: "(filter[name]=a|filter.nick=b|other=c)&filter[age][gt]=1|filter[age][lt]=0",
+			want:  "(name=a|nick=b)&(age[gt]=1|age[lt]=0)",
+		},
+		{
+			name:  "special keys",
+			value: "page[size]=10&page[offset]=20&sort=-age&fields[users]=id,name&_limit=5",
+			want:  "fields[users]=id,name&sort=age:desc&page[size]=10&page[offset]=20",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.value, opts...)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			text, err := q.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.want {
+				t.Errorf("Parse() = %s, want %s", text, tt.want)
+			}
+
+			again, err := ParseValues(q.ToValues(opts...), opts...)
+			if err != nil {
+				t.Fatalf("ParseValues(ToValues()) error = %v", err)
+			}
+
+			if again.Fingerprint() != q.Fingerprint() {
+				t.Errorf("ParseValues(ToValues()) = %v, want %v", again.Where, q.Where)
+			}
+		})
+	}
+}
+
+func TestParseWithSpecialKeysPage(t *testing.T) {
+	opts := []OptionQuery{
+		WithSpecialKeys(SpecialKeys{Limit: "per_page", Page: "page", Sort: "order_by", Fields: "select"}),
+	}
+
+	tests := []struct {
+		name       string
+		value      string
+		opts       []OptionQuery
+		wantOffset uint64
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name:       "page to offset",
+			value:      "page=3&per_page=20&order_by=-age&select=id",
+			wantOffset: 40,
+			wantText:   "select=id&order_by=age:desc&per_page=20&page=3",
+		},
+		{
+			name:       "first page",
+			value:      "per_page=20&page=1&name=foo",
+			wantOffset: 0,
+			wantText:   "per_page=20&page=1&name=foo",
+		},
+		{
+			name:       "page with default limit",
+			value:      "page=2",
+			opts:       []OptionQuery{WithDefaultLimit(50)},
+			wantOffset: 50,
+			wantText:   "per_page=50&page=2",
+		},
+		{
+			name:    "page without limit",
+			value:   "page=2",
+			wantErr: true,
+		},
+		{
+			name:    "page zero",
+			value:   "page=0&per_page=10",
+			wantErr: true,
+		},
+		{
+			name:    "page offset overflow",
+			value:   "page=18446744073709551615&per_page=100",
+			wantErr: true,
+		},
+		{
+			name:       "largest page offset",
+			value:      "page=18446744073709551615&per_page=1",
+			wantOffset: 18446744073709551614,
+			wantText:   "per_page=1&page=18446744073709551615",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.value, append(opts, tt.opts...)...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if q.GetOffset() != tt.wantOffset {
+				t.Errorf("Parse() Offset = %d, want %d", q.GetOffset(), tt.wantOffset)
+			}
+
+			text, err := q.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.wantText {
+				t.Errorf("MarshalText() = %s, want %s", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestParseWithSearchFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		opts     []OptionQuery
+		want     []Expression
+		wantText string
+	}{
+		{
+			name:  "search operator",
+			value: "body[search]=foo bar,baz",
+			want: []Expression{
+				NewExpressionCmp(OperatorSearch, "body", "foo bar,baz"),
+			},
+			wantText: "body[search]=foo+bar%2Cbaz",
+		},
+		{
+			name:  "global search",
+			value: "_q=foo bar&status=active",
+			opts:  []OptionQuery{WithSearchFields("title", "body")},
+			want: []Expression{
+				NewExpressionLogic(OperatorOr, []Expression{
+					NewExpressionCmp(OperatorSearch, "title", "foo bar"),
+					NewExpressionCmp(OperatorSearch, "body", "foo bar"),
+				}),
+				NewExpressionCmp(OperatorEq, "status", "active"),
+			},
+			wantText: "(title[search]=foo+bar|body[search]=foo+bar)&status=active",
+		},
+		{
+			name:     "global search single field",
+			value:    "q=foo",
+			opts:     []OptionQuery{WithSearchFields("title"), WithUnderscorePrefix(false)},
+			want:     []Expression{NewExpressionCmp(OperatorSearch, "title", "foo")},
+			wantText: "title[search]=foo",
+		},
+		{
+			name:  "empty global search",
+			value: "_q=%20&status=active",
+			opts:  []OptionQuery{WithSearchFields("title")},
+			want: []Expression{
+				NewExpressionCmp(OperatorEq, "status", "active"),
+			},
+			wantText: "status=active",
+		},
+		{
+			name:  "no search fields",
+			value: "q=foo",
+			opts:  []OptionQuery{WithUnderscorePrefix(false)},
+			want: []Expression{
+				NewExpressionCmp(OperatorEq, "q", "foo"),
+			},
+			wantText: "q=foo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got.Where, tt.want) {
+				t.Fatalf("Parse() Where = %v, want %v", got.Where, tt.want)
+			}
+
+			text, err := got.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.wantText {
+				t.Fatalf("MarshalText() = %s, want %s", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestParseWithNullValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		opts     []OptionQuery
+		want     []Expression
+		wantText string
+		wantErr  bool
+	}{
+		{
+			name:  "null literal",
+			value: "name=null&nick[ne]=null&age=null",
+			opts:  []OptionQuery{WithNullValue("null"), WithKeyType("age", ValueTypeNumber)},
+			want: []Expression{
+				NewExpressionCmp(OperatorIs, "name", nil),
+				NewExpressionCmp(OperatorIsNot, "nick", nil),
+				NewExpressionCmp(OperatorIs, "age", nil),
+			},
+			wantText: "name[is]=&nick[not]=&age[is]=",
+		},
+		{
+			name:  "custom literal",
+			value: `name=\0&nick=null&title[like]=\0`,
+			opts:  []OptionQuery{WithNullValue(`\0`)},
+			want: []Expression{
+				NewExpressionCmp(OperatorIs, "name", nil),
+				NewExpressionCmp(OperatorEq, "nick", "null"),
+				NewExpressionCmp(OperatorLike, "title", `\0`),
+			},
+			wantText: `name[is]=&nick=null&title[like]=%5C0`,
+		},
+		{
+			name:  "without option",
+			value: "name=null",
+			want: []Expression{
+				NewExpressionCmp(OperatorEq, "name", "null"),
+			},
+			wantText: "name=null",
+		},
+		{
+			name:  "distinct",
+			value: "name[isdistinct]=foo&nick[notdistinct]=null&title[isdistinct]=",
+			opts:  []OptionQuery{WithNullValue("null")},
+			want: []Expression{
+				NewExpressionCmp(OperatorIsDistinct, "name", "foo"),
+				NewExpressionCmp(OperatorNotDistinct, "nick", nil),
+				NewExpressionCmp(OperatorIsDistinct, "title", nil),
+			},
+			wantText: "name[isdistinct]=foo&nick[notdistinct]=&title[isdistinct]=",
+		},
+		{
+			name:  "is true and false",
+			value: "active[is]=true&deleted[not]=false&archived[is]=null",
+			want: []Expression{
+				NewExpressionCmp(OperatorIs, "active", true),
+				NewExpressionCmp(OperatorIsNot, "deleted", false),
+				NewExpressionCmp(OperatorIs, "archived", nil),
+			},
+			wantText: "active[is]=true&deleted[not]=false&archived[is]=",
+		},
+		{
+			name:    "is invalid value",
+			value:   "active[is]=yes",
+			wantErr: true,
+		},
+		{
+			name:    "is unknown value",
+			value:   "name[is]=foo",
+			wantErr: true,
+		},
+		{
+			name:    "not unknown value",
+			value:   "name[not]=foo",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Where, tt.want) {
+				t.Fatalf("Parse() Where = %v, want %v", got.Where, tt.want)
+			}
+
+			text, err := got.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.wantText {
+				t.Fatalf("MarshalText() = %s, want %s", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestParseCaseInsensitive(t *testing.T) {
+	q, err := Parse("email[ieq]=Bob@Example.com&username[ine]=Admin&role[iin]=Editor,admin")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Expression{
+		NewExpressionCmp(OperatorIEq, "email", "Bob@Example.com"),
+		NewExpressionCmp(OperatorINe, "username", "Admin"),
+		NewExpressionCmp(OperatorIIn, "role", []string{"Editor", "admin"}),
+	}
+	if !reflect.DeepEqual(q.Where, want) {
+		t.Fatalf("Parse() Where = %v, want %v", q.Where, want)
+	}
+
+	text, err := q.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+
+	if wantText := "email[ieq]=Bob%40Example.com&username[ine]=Admin&role[iin]=Editor,admin"; string(text) != wantText {
+		t.Fatalf("MarshalText() = %s, want %s", text, wantText)
+	}
+}
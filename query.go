@@ -1,5 +1,10 @@
 package query
 
+import (
+	"reflect"
+	"slices"
+)
+
 type Query struct {
 	Values map[string][]*ExpressionCmp
 
@@ -8,18 +13,24 @@ type Query struct {
 	Sort   []ExpressionSort
 	Offset *uint64
 	Limit  *uint64
+
+	// Group is the list of fields to group by, see Aggregate for the aggregate selections.
+	Group []string
+	// Having is the list of filters on aggregates, combined with AND.
+	Having []Expression
+
+	// keys are the special key names used by MarshalText, nil means the defaults.
+	keys *SpecialKeys
 }
 
+// GetValues returns every value of the field, typed values are formatted with their default format.
+//   - NULL checks like name[is]= have no value and are skipped.
 func (q *Query) GetValues(v string) []string {
 	if values, ok := q.Values[v]; ok {
 		result := make([]string, 0, len(values))
 
 		for _, v := range values {
-			if vList, ok := v.Value.([]string); ok {
-				result = append(result, vList...)
-			} else {
-				result = append(result, v.Value.(string))
-			}
+			result = append(result, cmpValues(v)...)
 		}
 
 		return result
@@ -28,15 +39,12 @@ func (q *Query) GetValues(v string) []string {
 	return nil
 }
 
+// GetValue returns the first value of the field, like GetValues.
 func (q *Query) GetValue(v string) string {
 	if values, ok := q.Values[v]; ok {
 		for _, v := range values {
-			if vList, ok := v.Value.([]string); ok {
-				if len(vList) > 0 {
-					return vList[0]
-				}
-			} else {
-				return v.Value.(string)
+			if vList := cmpValues(v); len(vList) > 0 {
+				return vList[0]
 			}
 		}
 	}
@@ -102,6 +110,38 @@ func New() *Query {
 	return &Query{}
 }
 
+// SetSpecialKeys sets the special key names used by MarshalText.
+//   - Empty names keep the default name.
+func (q *Query) SetSpecialKeys(keys SpecialKeys) *Query {
+	resolved := (&optionQuery{SpecialKeys: keys}).resolvedKeys()
+	q.keys = &resolved
+
+	return q
+}
+
+// specialKeys returns the special key names of the query.
+func (q *Query) specialKeys() SpecialKeys {
+	if q.keys != nil {
+		return *q.keys
+	}
+
+	return (&optionQuery{}).resolvedKeys()
+}
+
+// page returns the page number of the offset, false when the offset is not at a page boundary.
+func (q *Query) page() (uint64, bool) {
+	if q.Limit == nil || *q.Limit == 0 {
+		return 0, false
+	}
+
+	offset := q.GetOffset()
+	if offset%*q.Limit != 0 {
+		return 0, false
+	}
+
+	return offset / *q.Limit + 1, true
+}
+
 func (q *Query) AddField(fields ...string) *Query {
 	q.Select = append(q.Select, fields...)
 
@@ -114,6 +154,21 @@ func (q *Query) AddSort(sorts ...ExpressionSort) *Query {
 	return q
 }
 
+// AddGroup adds fields to group by.
+func (q *Query) AddGroup(fields ...string) *Query {
+	q.Group = append(q.Group, fields...)
+
+	return q
+}
+
+// AddHaving adds filters on aggregates, like F("count(*)").Gt(5).
+//   - Having comparisons are not added to Values.
+func (q *Query) AddHaving(exprs ...Expression) *Query {
+	q.Having = append(q.Having, exprs...)
+
+	return q
+}
+
 // SetLimit sets the limit for the query.
 //   - if limit is <= 0, it means no limit.
 func (q *Query) SetLimit(limit uint64) *Query {
@@ -169,3 +224,103 @@ func (q *Query) valuesExpression(expr Expression) {
 		}
 	}
 }
+
+// Clone returns a deep copy of the query.
+//   - Comparisons shared between Where and Values stay shared in the copy.
+func (q *Query) Clone() *Query {
+	if q == nil {
+		return nil
+	}
+
+	cmps := make(map[*ExpressionCmp]*ExpressionCmp)
+
+	result := &Query{
+		Select: slices.Clone(q.Select),
+		Sort:   slices.Clone(q.Sort),
+		Offset: q.CloneOffset(),
+		Limit:  q.CloneLimit(),
+		keys:   q.keys,
+	}
+
+	if q.Where != nil {
+		result.Where = make([]Expression, 0, len(q.Where))
+		for _, expr := range q.Where {
+			result.Where = append(result.Where, cloneExpression(expr, cmps))
+		}
+	}
+
+	result.Group = slices.Clone(q.Group)
+	if q.Having != nil {
+		result.Having = make([]Expression, 0, len(q.Having))
+		for _, expr := range q.Having {
+			result.Having = append(result.Having, cloneExpression(expr, cmps))
+		}
+	}
+
+	if q.Values != nil {
+		result.Values = make(map[string][]*ExpressionCmp, len(q.Values))
+		for field, values := range q.Values {
+			cloned := make([]*ExpressionCmp, 0, len(values))
+			for _, cmp := range values {
+				cloned = append(cloned, cloneCmp(cmp, cmps))
+			}
+
+			result.Values[field] = cloned
+		}
+	}
+
+	return result
+}
+
+func cloneExpression(expr Expression, cmps map[*ExpressionCmp]*ExpressionCmp) Expression {
+	switch expr := expr.(type) {
+	case *ExpressionCmp:
+		return cloneCmp(expr, cmps)
+	case *ExpressionLogic:
+		list := make([]Expression, 0, len(expr.List))
+		for _, e := range expr.List {
+			list = append(list, cloneExpression(e, cmps))
+		}
+
+		return NewExpressionLogic(expr.Operator, list)
+	}
+
+	return expr
+}
+
+// cloneValue copies list values of any slice type, like []string, []int or []any from the builder.
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case []string:
+		return slices.Clone(v)
+	case []bool:
+		return slices.Clone(v)
+	case []any:
+		return slices.Clone(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice || rv.IsNil() {
+		return value
+	}
+
+	cloned := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+	reflect.Copy(cloned, rv)
+
+	return cloned.Interface()
+}
+
+func cloneCmp(cmp *ExpressionCmp, cmps map[*ExpressionCmp]*ExpressionCmp) *ExpressionCmp {
+	if cmp == nil {
+		return nil
+	}
+
+	if cloned, ok := cmps[cmp]; ok {
+		return cloned
+	}
+
+	cloned := NewExpressionCmp(cmp.Operator, cmp.Field, cloneValue(cmp.Value))
+	cmps[cmp] = cloned
+
+	return cloned
+}
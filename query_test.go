@@ -268,3 +268,71 @@ func TestParseQuery_WithUnderscorePrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestQuery_Clone(t *testing.T) {
+	q, err := Parse("name=foo,bar|nick=bar&age[lt]=1&_sort=-age&_limit=10&_offset=5&_fields=id,name&_events=true")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	c := q.Clone()
+	if !reflect.DeepEqual(c, q) {
+		t.Fatalf("Clone() = %#v, want %#v", c, q)
+	}
+
+	c.Values["name"][0].Value.([]string)[0] = "changed"
+	*c.Limit = 20
+	c.Select[0] = "changed"
+
+	if q.GetValue("name") != "foo" || *q.Limit != 10 || q.Select[0] != "id" {
+		t.Fatalf("Clone() shares memory with the original query")
+	}
+
+	if c.Where[0].(*ExpressionLogic).List[0] != c.Values["name"][0] {
+		t.Fatalf("Clone() does not share comparisons between Where and Values")
+	}
+	built := Where(F("id").In(1, 2), NewExpressionCmp(OperatorIn, "age", []int{1, 2}))
+	cb := built.Clone()
+	cb.Where[0].(*ExpressionCmp).Value.([]any)[0] = 3
+	cb.Where[1].(*ExpressionCmp).Value.([]int)[0] = 3
+
+	if built.Where[0].(*ExpressionCmp).Value.([]any)[0] != 1 || built.Where[1].(*ExpressionCmp).Value.([]int)[0] != 1 {
+		t.Fatalf("Clone() shares list values of type []any or []int with the original query")
+	}
+}
+
+func TestQuery_GetValue(t *testing.T) {
+	q, err := Parse("name=null&deleted[is]=&active[is]=true&age=18&tags[acontains]=1,2&id=null,3",
+		WithNullValue("null"),
+		WithKeyType("tags", ValueTypeNumber),
+	)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantValues []string
+	}{
+		{key: "name", wantValue: "", wantValues: []string{}},
+		{key: "deleted", wantValue: "", wantValues: []string{}},
+		{key: "active", wantValue: "true", wantValues: []string{"true"}},
+		{key: "age", wantValue: "18", wantValues: []string{"18"}},
+		{key: "tags", wantValue: "1", wantValues: []string{"1", "2"}},
+		{key: "id", wantValue: "null", wantValues: []string{"null", "3"}},
+		{key: "missing", wantValue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := q.GetValue(tt.key); got != tt.wantValue {
+				t.Errorf("GetValue() = %q, want %q", got, tt.wantValue)
+			}
+
+			if got := q.GetValues(tt.key); !reflect.DeepEqual(got, tt.wantValues) {
+				t.Errorf("GetValues() = %#v, want %#v", got, tt.wantValues)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ParseValues parses decoded url.Values into a Query struct.
+//   - Keys are handled in sorted order, repeated values of a key in their order.
+//   - Groups encoded by ToValues, like key "(name" with value "a|nick=b)", are understood.
+func ParseValues(values url.Values, opts ...OptionQuery) (*Query, error) {
+	p := newParser(newOptionQuery(opts...))
+	result := New()
+
+	if err := p.parseValues(result, values); err != nil {
+		return nil, err
+	}
+
+	if err := p.finish(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ParseJSON parses the JSON AST format into a Query struct.
+//
+//	{
+//	  "where": [
+//	    {"field": "age", "op": "gt", "value": 18},
+//	    {"or": [{"field": "name", "op": "ilike", "value": "%a%"}, {"field": "nick", "value": "b"}]}
+//	  ],
+//	  "sort": ["-age"],
+//	  "fields": ["id", "name"],
+//	  "limit": 10,
+//	  "offset": 0
+//	}
+//
+// Values are converted to strings and parsed with the same options as the query string,
+// arrays are joined with commas and objects are kept as JSON text for the kv operator.
+// Aggregations use "group" for the group by fields and "having" with the same nodes as "where",
+// the fields of having must be aggregates like "count(*)", both need WithAggregation.
+// WithFilterKey and WithFilterPrefix only apply to query strings, the JSON fields are used as is.
+func ParseJSON(data []byte, opts ...OptionQuery) (*Query, error) {
+	p := newParser(newOptionQuery(opts...))
+	result := New()
+
+	if err := p.parseJSON(result, data); err != nil {
+		return nil, err
+	}
+
+	if err := p.finish(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ParseRequest parses the URL query of the request and, when present, the body.
+//   - application/x-www-form-urlencoded bodies use the query string syntax.
+//   - application/json bodies use the JSON AST format of ParseJSON.
+//   - Body values are added after the URL query values.
+func ParseRequest(r *http.Request, opts ...OptionQuery) (*Query, error) {
+	p := newParser(newOptionQuery(opts...))
+	result := New()
+
+	if err := p.parseRaw(result, r.URL.RawQuery); err != nil {
+		return nil, err
+	}
+
+	if r.Body != nil && r.Body != http.NoBody {
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		switch contentType {
+		case "application/x-www-form-urlencoded":
+			body, err := p.readBody(r.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.parseRaw(result, string(body)); err != nil {
+				return nil, err
+			}
+		case "application/json":
+			body, err := p.readBody(r.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.parseJSON(result, body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := p.finish(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readBody reads the body, stopping early when it is longer than MaxQueryLength.
+func (p *parser) readBody(body io.Reader) ([]byte, error) {
+	if p.o.MaxQueryLength > 0 {
+		body = io.LimitReader(body, int64(p.o.MaxQueryLength)+1)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	if p.o.MaxQueryLength > 0 && len(data) > p.o.MaxQueryLength {
+		return nil, &LimitError{Limit: LimitQueryLength, Max: p.o.MaxQueryLength, Value: len(data)}
+	}
+
+	return data, nil
+}
+
+func (p *parser) parseValues(result *Query, values url.Values) error {
+	if p.o.MaxQueryLength > 0 {
+		length := 0
+		for key, vs := range values {
+			for _, v := range vs {
+				length += len(key) + len(v) + 2
+			}
+		}
+
+		if length > p.o.MaxQueryLength {
+			return &LimitError{Limit: LimitQueryLength, Max: p.o.MaxQueryLength, Value: length}
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if err := p.parsePair(result, key+"="+value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type jsonQuery struct {
+	Where  []jsonExpression `json:"where"`
+	Sort   []string         `json:"sort"`
+	Fields []string         `json:"fields"`
+	Group  []string         `json:"group"`
+	Having []jsonExpression `json:"having"`
+	Limit  *uint64          `json:"limit"`
+	Offset *uint64          `json:"offset"`
+}
+
+type jsonExpression struct {
+	Field string          `json:"field"`
+	Op    string          `json:"op"`
+	Value json.RawMessage `json:"value"`
+
+	And []jsonExpression `json:"and"`
+	Or  []jsonExpression `json:"or"`
+}
+
+func (p *parser) parseJSON(result *Query, data []byte) error {
+	var jq jsonQuery
+	if err := json.Unmarshal(data, &jq); err != nil {
+		return fmt.Errorf("invalid json query: %w", err)
+	}
+
+	p.json = true
+	defer func() { p.json = false }()
+
+	for _, field := range jq.Fields {
+		if field != "" {
+			result.Select = append(result.Select, field)
+		}
+	}
+
+	if !p.o.Aggregation {
+		jq.Group, jq.Having = nil, nil
+	}
+
+	for _, field := range jq.Group {
+		if field != "" {
+			result.Group = append(result.Group, field)
+		}
+	}
+
+	if len(jq.Sort) > 0 {
+		result.Sort = parseSort(strings.Join(jq.Sort, ","))
+	}
+
+	if jq.Limit != nil {
+		result.Limit = jq.Limit
+	}
+
+	if jq.Offset != nil {
+		result.Offset = jq.Offset
+	}
+
+	for _, je := range jq.Where {
+		expr, err := p.parseJSONExpression(je, 0)
+		if err != nil {
+			return err
+		}
+
+		if err := p.addWhere(result, expr); err != nil {
+			return err
+		}
+	}
+
+	p.having = true
+	defer func() { p.having = false }()
+
+	for _, je := range jq.Having {
+		expr, err := p.parseJSONExpression(je, 0)
+		if err != nil {
+			return err
+		}
+
+		if expr != nil {
+			result.Having = append(result.Having, expr)
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) parseJSONExpression(je jsonExpression, depth int) (Expression, error) {
+	switch {
+	case je.And != nil || je.Or != nil:
+		if je.And != nil && je.Or != nil {
+			return nil, fmt.Errorf("invalid json expression: both and, or are set")
+		}
+
+		depth++
+		if p.o.MaxDepth > 0 && depth > p.o.MaxDepth {
+			return nil, &LimitError{Limit: LimitDepth, Max: p.o.MaxDepth, Value: depth}
+		}
+
+		operator, list := OperatorAnd, je.And
+		if je.Or != nil {
+			operator, list = OperatorOr, je.Or
+
+			if err := p.addOrBranches(len(list)); err != nil {
+				return nil, err
+			}
+		}
+
+		exprs := make([]Expression, 0, len(list))
+		for _, sub := range list {
+			expr, err := p.parseJSONExpression(sub, depth)
+			if err != nil {
+				return nil, err
+			}
+
+			if expr != nil {
+				exprs = append(exprs, expr)
+			}
+		}
+
+		return NewExpressionLogic(operator, exprs), nil
+	case je.Field == "":
+		return nil, fmt.Errorf("invalid json expression: field is empty")
+	}
+
+	value, err := jsonValueString(je.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json value for field [%s]: %w", je.Field, err)
+	}
+
+	key := je.Field
+	if je.Op != "" {
+		key += "[" + je.Op + "]"
+	}
+
+	exp, err := p.parseExpression(key, value)
+	if exp == nil {
+		// Avoid a non-nil interface holding a nil pointer.
+		return nil, err
+	}
+
+	return exp, nil
+}
+
+// jsonValueString converts a JSON value to the query string form.
+func jsonValueString(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	switch raw[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+
+		return s, nil
+	case '[':
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return "", err
+		}
+
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			v, err := jsonValueString(item)
+			if err != nil {
+				return "", err
+			}
+
+			values = append(values, v)
+		}
+
+		return strings.Join(values, ","), nil
+	case 'n':
+		return "", nil
+	default:
+		// numbers, booleans and objects keep their JSON text
+		return string(raw), nil
+	}
+}
@@ -0,0 +1,192 @@
+package query
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	values := url.Values{
+		"name":    {"a&b"},
+		"age[gt]": {"18"},
+		"(nick":   {"x|email=y)"},
+		"_limit":  {"10"},
+		"_sort":   {"-age"},
+	}
+
+	q, err := ParseValues(values)
+	if err != nil {
+		t.Fatalf("ParseValues() error = %v", err)
+	}
+
+	text, err := q.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+
+	want := "_sort=age:desc&_limit=10&(nick=x|email=y)&age[gt]=18&name=a%26b"
+	if string(text) != want {
+		t.Errorf("ParseValues() = %s, want %s", text, want)
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	filterDot := WithFilterKey(func(key string) (string, bool) {
+		return strings.CutPrefix(key, "filter.")
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		opts        []OptionQuery
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:   "url query",
+			method: "GET",
+			target: "/users?name=foo|nick=bar&_limit=5",
+			want:   "_limit=5&(name=foo|nick=bar)",
+		},
+		{
+			name:        "form body",
+			method:      "POST",
+			target:      "/users?_limit=5",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "age%5Bgt%5D=18&(name=a|nick=b)",
+			want:        "_limit=5&age[gt]=18&(name=a|nick=b)",
+		},
+		{
+			name:        "json body",
+			method:      "POST",
+			target:      "/users",
+			contentType: "application/json; charset=utf-8",
+			body: `{
+				"where": [
+					{"field": "age", "op": "gt", "value": 18},
+					{"or": [{"field": "name", "op": "ilike", "value": "%a%"}, {"field": "id", "value": [1, 2]}]},
+					{"field": "meta", "op": "kv", "value": {"a": 1}}
+				],
+				"sort": ["-age"],
+				"fields": ["id", "name"],
+				"limit": 10
+			}`,
+			want: "_fields=id,name&_sort=age:desc&_limit=10&age[gt]=18&(name[ilike]=%25a%25|id[in]=1,2)&meta[kv]=eyJhIjogMX0",
+		},
+		{
+			name:        "json body max depth",
+			method:      "POST",
+			target:      "/users",
+			contentType: "application/json",
+			body:        `{"where": [{"and": [{"or": [{"field": "a", "value": 1}]}]}]}`,
+			opts:        []OptionQuery{WithMaxDepth(1)},
+			wantErr:     true,
+		},
+		{
+			name:        "body too long",
+			method:      "POST",
+			target:      "/users",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=abcdefghijklmnopqrstuvwxyz",
+			opts:        []OptionQuery{WithMaxQueryLength(10)},
+			wantErr:     true,
+		},
+		{
+			name:   "filter key prefix",
+			method: "GET",
+			target: "/users?filter.name=foo&format=json&include=roles&(filter.age[gt]=1|other=2)&_limit=5",
+			opts:   []OptionQuery{filterDot},
+			want:   "_limit=5&name=foo&(age[gt]=1)",
+		},
+		{
+			name:        "filter prefix with json body",
+			method:      "POST",
+			target:      "/users?filter[name]=foo&format=json",
+			contentType: "application/json",
+			body:        `{"where": [{"field": "age", "op": "gt", "value": 18}]}`,
+			opts:        []OptionQuery{WithFilterPrefix("filter")},
+			want:        "name=foo&age[gt]=18",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			q, err := ParseRequest(r, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			text, err := q.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.want {
+				t.Errorf("ParseRequest() = %s, want %s", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		opts    []OptionQuery
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "where",
+			body: `{"where": [{"field": "age", "op": "gt", "value": 18}, {"or": [{"field": "name", "value": "a"}, {"field": "nick", "value": "b"}]}]}`,
+			want: "age[gt]=18&(name=a|nick=b)",
+		},
+		{
+			name: "filter prefix is not applied",
+			body: `{"where": [{"field": "age", "op": "gt", "value": 18}, {"or": [{"field": "name", "value": "a"}, {"field": "nick", "value": "b"}]}]}`,
+			opts: []OptionQuery{WithFilterPrefix("filter")},
+			want: "age[gt]=18&(name=a|nick=b)",
+		},
+		{
+			name:    "invalid json",
+			body:    `{"where": `,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseJSON([]byte(tt.body), tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			text, err := q.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.want {
+				t.Errorf("ParseJSON() = %s, want %s", text, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package query
+
+// Transform rewrites the where tree, calling fn for every expression in post-order.
+//   - Children of an ExpressionLogic are transformed before the group itself,
+//     fn gets a new group with the transformed children.
+//   - Returning the same expression keeps it, returning another expression replaces it
+//     (e.g. a single comparison can be expanded to an ExpressionLogic).
+//   - Returning nil or an ExpressionLogic without children drops the expression,
+//     groups left without children are dropped too.
+//   - Where is replaced only when every call succeeds, on error the query is unchanged.
+//   - Values is rebuilt afterwards, entries skipped from Where by the parse options are kept.
+func (q *Query) Transform(fn func(Expression) (Expression, error)) error {
+	where := make([]Expression, 0, len(q.Where))
+	for _, expr := range q.Where {
+		transformed, err := transformExpression(expr, fn)
+		if err != nil {
+			return err
+		}
+
+		if transformed != nil {
+			where = append(where, transformed)
+		}
+	}
+
+	inWhere := make(map[*ExpressionCmp]struct{})
+	for _, expr := range q.Where {
+		collectCmp(expr, inWhere)
+	}
+
+	q.Where = where
+
+	values := q.Values
+	q.Values = nil
+	for field, cmps := range values {
+		for _, cmp := range cmps {
+			if _, ok := inWhere[cmp]; ok {
+				continue
+			}
+
+			if q.Values == nil {
+				q.Values = make(map[string][]*ExpressionCmp)
+			}
+
+			q.Values[field] = append(q.Values[field], cmp)
+		}
+	}
+
+	for _, expr := range q.Where {
+		q.valuesExpression(expr)
+	}
+
+	return nil
+}
+
+func transformExpression(expr Expression, fn func(Expression) (Expression, error)) (Expression, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	if exprLogic, ok := expr.(*ExpressionLogic); ok {
+		list := make([]Expression, 0, len(exprLogic.List))
+		for _, e := range exprLogic.List {
+			transformed, err := transformExpression(e, fn)
+			if err != nil {
+				return nil, err
+			}
+
+			if transformed != nil {
+				list = append(list, transformed)
+			}
+		}
+
+		if len(list) == 0 {
+			return nil, nil
+		}
+
+		// A new group, so the tree of the query is untouched until every call succeeds.
+		expr = &ExpressionLogic{Operator: exprLogic.Operator, List: list}
+	}
+
+	result, err := fn(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if exprLogic, ok := result.(*ExpressionLogic); ok && len(exprLogic.List) == 0 {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+// collectCmp adds every comparison of the expression to the set.
+func collectCmp(expr Expression, set map[*ExpressionCmp]struct{}) {
+	switch expr := expr.(type) {
+	case *ExpressionCmp:
+		set[expr] = struct{}{}
+	case *ExpressionLogic:
+		for _, e := range expr.List {
+			collectCmp(e, set)
+		}
+	}
+}
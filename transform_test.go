@@ -0,0 +1,158 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestQuery_Transform(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		fn         func(Expression) (Expression, error)
+		wantText   string
+		wantValues []string
+	}{
+		{
+			name:  "alias field",
+			value: "nick=foo|name=bar&age=1",
+			fn: func(e Expression) (Expression, error) {
+				if cmp, ok := e.(*ExpressionCmp); ok && cmp.Field == "nick" {
+					cmp.Field = "nickname"
+				}
+
+				return e, nil
+			},
+			wantText:   "(nickname=foo|name=bar)&age=1",
+			wantValues: []string{"age", "name", "nickname"},
+		},
+		{
+			name:  "map enum labels",
+			value: "status=active,deleted",
+			fn: func(e Expression) (Expression, error) {
+				codes := map[string]string{"active": "1", "deleted": "9"}
+				if cmp, ok := e.(*ExpressionCmp); ok && cmp.Field == "status" {
+					if values, ok := cmp.Value.([]string); ok {
+						for i, v := range values {
+							values[i] = codes[v]
+						}
+					}
+				}
+
+				return e, nil
+			},
+			wantText:   "status[in]=1,9",
+			wantValues: []string{"status"},
+		},
+		{
+			name:  "drop forbidden collapses groups",
+			value: "(secret=1|secret=2)&name=foo&_events=true",
+			fn: func(e Expression) (Expression, error) {
+				if cmp, ok := e.(*ExpressionCmp); ok && cmp.Field == "secret" {
+					return nil, nil
+				}
+
+				return e, nil
+			},
+			wantText:   "name=foo",
+			wantValues: []string{"_events", "name"},
+		},
+		{
+			name:  "expand comparison",
+			value: "q=foo",
+			fn: func(e Expression) (Expression, error) {
+				if cmp, ok := e.(*ExpressionCmp); ok && cmp.Field == "q" {
+					return NewExpressionLogic(OperatorOr, []Expression{
+						NewExpressionCmp(OperatorILike, "name", cmp.Value),
+						NewExpressionCmp(OperatorILike, "email", cmp.Value),
+					}), nil
+				}
+
+				return e, nil
+			},
+			wantText:   "(name[ilike]=foo|email[ilike]=foo)",
+			wantValues: []string{"email", "name"},
+		},
+		{
+			name:  "empty group is dropped",
+			value: "(a=1|b=2)&name=foo",
+			fn: func(e Expression) (Expression, error) {
+				if logic, ok := e.(*ExpressionLogic); ok && logic.Operator == OperatorOr {
+					return NewExpressionLogic(OperatorOr, nil), nil
+				}
+
+				return e, nil
+			},
+			wantText:   "name=foo",
+			wantValues: []string{"name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.value)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if err := q.Transform(tt.fn); err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+
+			text, err := q.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+
+			if string(text) != tt.wantText {
+				t.Errorf("MarshalText() = %s, want %s", text, tt.wantText)
+			}
+
+			var keys []string
+			for k := range q.Values {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+
+			if !reflect.DeepEqual(keys, tt.wantValues) {
+				t.Errorf("Values keys = %v, want %v", keys, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestQuery_TransformError(t *testing.T) {
+	q, err := Parse("(secret=1|name=foo)&age=1&secret=2&nick=bar")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := q.Clone()
+	wantErr := errors.New("forbidden")
+
+	err = q.Transform(func(e Expression) (Expression, error) {
+		if cmp, ok := e.(*ExpressionCmp); ok {
+			switch cmp.Field {
+			case "secret":
+				return nil, nil
+			case "nick":
+				return nil, wantErr
+			}
+		}
+
+		return e, nil
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Transform() error = %v, want %v", err, wantErr)
+	}
+
+	if !reflect.DeepEqual(q.Where, want.Where) {
+		t.Errorf("Transform() Where = %v, want unchanged %v", q.Where, want.Where)
+	}
+
+	if !reflect.DeepEqual(q.Values, want.Values) {
+		t.Errorf("Transform() Values = %v, want unchanged %v", q.Values, want.Values)
+	}
+}
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"fmt"
 	"strconv"
 )
 
@@ -44,6 +45,40 @@ func StringsToType(ss []string, valueType ValueType) (any, error) {
 	}
 }
 
+// arrayItems returns the items of an array operator typed with the value type.
+//   - Numbers are int64 or float64, so the items match the type of a numeric array column.
+func arrayItems(ss []string, valueType ValueType) (any, error) {
+	if valueType != ValueTypeNumber {
+		return StringsToType(ss, valueType)
+	}
+
+	result := make([]any, 0, len(ss))
+	for _, s := range ss {
+		v, err := parseNumber(s)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, v)
+	}
+
+	return result, nil
+}
+
+// parseNumber returns the number as int64 when it is an integer, otherwise as float64.
+func parseNumber(s string) (any, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number [%s]", s)
+	}
+
+	return f, nil
+}
+
 func parseBoolean(s string) (bool, error) {
 	return strconv.ParseBool(s)
 }
@@ -1,9 +1,15 @@
 package query
 
 import (
+	"context"
 	"fmt"
 	"math/big"
+	"reflect"
+	"regexp"
 	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 type funcType int
@@ -15,16 +21,26 @@ const (
 	offsetType
 	limitType
 	sortType
+	depthType
+	comparisonsType
+	orBranchesType
+	listItemsType
+	groupType
+	aggregateType
 )
 
 type Validator struct {
-	fields []func(q *Query) error
-	values []func(q *Query) error
-	value  map[string][]func(q *Query) error
-
-	offset []func(q *Query) error
-	limit  []func(q *Query) error
-	sort   []func(q *Query) error
+	fields []func(ctx context.Context, q *Query) error
+	values []func(ctx context.Context, q *Query) error
+	value  map[string][]func(ctx context.Context, q *Query) error
+
+	offset    []func(ctx context.Context, q *Query) error
+	limit     []func(ctx context.Context, q *Query) error
+	sort      []func(ctx context.Context, q *Query) error
+	group     []func(ctx context.Context, q *Query) error
+	aggregate []func(ctx context.Context, q *Query) error
+	where     []func(ctx context.Context, q *Query) error
+	query     []func(ctx context.Context, q *Query) error
 }
 
 type (
@@ -35,7 +51,7 @@ type (
 
 func NewValidator(opts ...OptionValidateSet) (*Validator, error) {
 	v := &Validator{
-		value: make(map[string][]func(q *Query) error),
+		value: make(map[string][]func(ctx context.Context, q *Query) error),
 	}
 
 	for _, opt := range opts {
@@ -119,11 +135,107 @@ func WithSort(opts ...optionValidateFunc) OptionValidateSet {
 	}
 }
 
+// WithGroup validates the group by fields.
+func WithGroup(opts ...optionValidateFunc) OptionValidateSet {
+	return func(v *Validator) error {
+		for _, opt := range opts {
+			if err := opt("", v, groupType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithAggregate validates the aggregates of the selection and the having clauses, like count(*) and sum(amount).
+//   - Aggregates are checked in their lowercase form, e.g. WithIn("count(*)", "sum(amount)").
+//   - The argument fields of the aggregates are also checked by the WithField rules.
+func WithAggregate(opts ...optionValidateFunc) OptionValidateSet {
+	return func(v *Validator) error {
+		for _, opt := range opts {
+			if err := opt("", v, aggregateType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithDepth validates the nesting depth of expression groups in the where tree, like WithMaxDepth of the parser.
+//   - a=1 has depth 0, a=1|b=2 depth 1 and a=1|(b=2&c=3) depth 2.
+func WithDepth(opts ...optionValidateFunc) OptionValidateSet {
+	return func(v *Validator) error {
+		for _, opt := range opts {
+			if err := opt("", v, depthType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithComparisons validates the total number of comparisons in the where tree.
+func WithComparisons(opts ...optionValidateFunc) OptionValidateSet {
+	return func(v *Validator) error {
+		for _, opt := range opts {
+			if err := opt("", v, comparisonsType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithOrBranches validates the total number of OR branches in the where tree.
+func WithOrBranches(opts ...optionValidateFunc) OptionValidateSet {
+	return func(v *Validator) error {
+		for _, opt := range opts {
+			if err := opt("", v, orBranchesType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithListItems validates the number of values of every comparison, like 'in' and 'jin' lists.
+func WithListItems(opts ...optionValidateFunc) OptionValidateSet {
+	return func(v *Validator) error {
+		for _, opt := range opts {
+			if err := opt("", v, listItemsType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithQueryCustom adds a custom rule with access to the whole query.
+//   - ctx is the context given to ValidateContext.
+func WithQueryCustom(fn func(ctx context.Context, q *Query) error) OptionValidateSet {
+	return func(v *Validator) error {
+		if fn == nil {
+			return fmt.Errorf("custom function is nil")
+		}
+
+		v.query = append(v.query, fn)
+
+		return nil
+	}
+}
+
 // ////////////////////////////////////////////////////////////////////////////
 // ////////////////////////////////////////////////////////////////////////////
 
 // WithMin to validate the minimum of a value.
-//   - Usable for 'WithValue', WithSort', 'WithLimit'
+//   - Usable for 'WithValue', 'WithOffset', 'WithLimit'
+//   - For 'WithValue' every comparison of the key is checked, whatever the operator.
 func WithMin(min string) optionValidateFunc {
 	return func(key string, v *Validator, t funcType) error {
 		vMinBig, ok := new(big.Float).SetString(min)
@@ -133,7 +245,7 @@ func WithMin(min string) optionValidateFunc {
 
 		switch t {
 		case offsetType:
-			v.offset = append(v.offset, func(q *Query) error {
+			v.offset = append(v.offset, func(ctx context.Context, q *Query) error {
 				if q.Offset != nil {
 					if new(big.Float).SetUint64(*q.Offset).Cmp(vMinBig) < 0 {
 						return fmt.Errorf("offset [%d] is less than min [%s]", q.Offset, min)
@@ -143,7 +255,7 @@ func WithMin(min string) optionValidateFunc {
 				return nil
 			})
 		case limitType:
-			v.limit = append(v.limit, func(q *Query) error {
+			v.limit = append(v.limit, func(ctx context.Context, q *Query) error {
 				if q.Limit != nil {
 					if new(big.Float).SetUint64(*q.Limit).Cmp(vMinBig) < 0 {
 						return fmt.Errorf("limit [%d] is less than min [%s]", q.Limit, min)
@@ -152,37 +264,16 @@ func WithMin(min string) optionValidateFunc {
 				return nil
 			})
 		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
 				for _, cmp := range q.Values[key] {
-					if cmp.Operator == OperatorEq && cmp.Value != nil {
-						cmpStr, ok := cmp.Value.(string)
-						if !ok {
-							return fmt.Errorf("value [%v] is not a string for number", cmp.Value)
-						}
-
-						cmpBig, ok := new(big.Float).SetString(cmpStr)
+					for _, val := range cmpValues(cmp) {
+						cmpBig, ok := new(big.Float).SetString(val)
 						if !ok {
-							return fmt.Errorf("value [%s] is not a number", cmpStr)
+							return fmt.Errorf("value [%s] is not a number", val)
 						}
 
 						if cmpBig.Cmp(vMinBig) < 0 {
-							return fmt.Errorf("value [%s] is less than min [%s]", cmpStr, min)
-						}
-					}
-					if cmp.Operator == OperatorIn && cmp.Value != nil {
-						cmpIn, ok := cmp.Value.([]string)
-						if !ok {
-							return fmt.Errorf("value [%v] is not a slice for number", cmp.Value)
-						}
-						for _, val := range cmpIn {
-							cmpBig, ok := new(big.Float).SetString(val)
-							if !ok {
-								return fmt.Errorf("value [%s] is not a number", val)
-							}
-
-							if cmpBig.Cmp(vMinBig) < 0 {
-								return fmt.Errorf("value [%s] is less than min [%s]", val, min)
-							}
+							return fmt.Errorf("value [%s] is less than min [%s]", val, min)
 						}
 					}
 				}
@@ -196,7 +287,9 @@ func WithMin(min string) optionValidateFunc {
 }
 
 // WithMax to validate the maximum of a value.
-//   - Usable for 'WithValue', 'WithLimit', 'WithOffset'
+//   - Usable for 'WithValue', 'WithLimit', 'WithOffset', 'WithDepth', 'WithComparisons', 'WithOrBranches', 'WithListItems'
+//   - For 'WithValue' every comparison of the key is checked, whatever the operator.
+//   - Complexity rules return a *LimitError.
 func WithMax(max string) optionValidateFunc {
 	return func(key string, v *Validator, t funcType) error {
 		vMaxBig, ok := new(big.Float).SetString(max)
@@ -205,8 +298,36 @@ func WithMax(max string) optionValidateFunc {
 		}
 
 		switch t {
+		case depthType, comparisonsType, orBranchesType, listItemsType:
+			vMax, err := strconv.Atoi(max)
+			if err != nil {
+				return fmt.Errorf("max value [%s] is not a valid integer", max)
+			}
+
+			v.where = append(v.where, func(ctx context.Context, q *Query) error {
+				c := q.complexity()
+
+				var limit LimitType
+				var value int
+				switch t {
+				case depthType:
+					limit, value = LimitDepth, c.Depth
+				case comparisonsType:
+					limit, value = LimitComparisons, c.Comparisons
+				case orBranchesType:
+					limit, value = LimitOrBranches, c.OrBranches
+				case listItemsType:
+					limit, value = LimitListItems, c.ListItems
+				}
+
+				if value > vMax {
+					return &LimitError{Limit: limit, Max: vMax, Value: value}
+				}
+
+				return nil
+			})
 		case offsetType:
-			v.offset = append(v.offset, func(q *Query) error {
+			v.offset = append(v.offset, func(ctx context.Context, q *Query) error {
 				if q.Offset != nil {
 					if new(big.Float).SetUint64(*q.Offset).Cmp(vMaxBig) > 0 {
 						return fmt.Errorf("offset [%d] is greater than max [%s]", q.Offset, max)
@@ -216,7 +337,7 @@ func WithMax(max string) optionValidateFunc {
 				return nil
 			})
 		case limitType:
-			v.limit = append(v.limit, func(q *Query) error {
+			v.limit = append(v.limit, func(ctx context.Context, q *Query) error {
 				if q.Limit != nil {
 					if new(big.Float).SetUint64(*q.Limit).Cmp(vMaxBig) > 0 {
 						return fmt.Errorf("limit [%d] is greater than max [%s]", q.Limit, max)
@@ -225,37 +346,16 @@ func WithMax(max string) optionValidateFunc {
 				return nil
 			})
 		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
 				for _, cmp := range q.Values[key] {
-					if cmp.Operator == OperatorEq && cmp.Value != nil {
-						cmpStr, ok := cmp.Value.(string)
+					for _, val := range cmpValues(cmp) {
+						cmpBig, ok := new(big.Float).SetString(val)
 						if !ok {
-							return fmt.Errorf("value [%v] is not a string for number", cmp.Value)
-						}
-
-						cmpBig, ok := new(big.Float).SetString(cmpStr)
-						if !ok {
-							return fmt.Errorf("value [%s] is not a number", cmpStr)
+							return fmt.Errorf("value [%s] is not a number", val)
 						}
 
 						if cmpBig.Cmp(vMaxBig) > 0 {
-							return fmt.Errorf("value [%s] is greater than max [%s]", cmpStr, max)
-						}
-					}
-					if cmp.Operator == OperatorIn && cmp.Value != nil {
-						cmpIn, ok := cmp.Value.([]string)
-						if !ok {
-							return fmt.Errorf("value [%v] is not a slice for number", cmp.Value)
-						}
-						for _, val := range cmpIn {
-							cmpBig, ok := new(big.Float).SetString(val)
-							if !ok {
-								return fmt.Errorf("value [%s] is not a number", val)
-							}
-
-							if cmpBig.Cmp(vMaxBig) > 0 {
-								return fmt.Errorf("value [%s] is greater than max [%s]", val, max)
-							}
+							return fmt.Errorf("value [%s] is greater than max [%s]", val, max)
 						}
 					}
 				}
@@ -269,19 +369,30 @@ func WithMax(max string) optionValidateFunc {
 }
 
 // WithIn checks if the value is in the list of values.
-//   - Usable for 'WithValue', 'WithSort', 'WithValues', 'WithFields'
+//   - Usable for 'WithValue', 'WithSort', 'WithValues', 'WithFields', 'WithGroup', 'WithAggregate'
+//   - For 'WithValue' every comparison of the key is checked, whatever the operator.
+//     Values of the ieq, ine and iin operators are matched case insensitive.
+//   - For 'WithFields' the argument of an aggregate is checked, e.g. amount for sum(amount).
 func WithIn(values ...string) optionValidateFunc {
-	valuesMap := make(map[string]struct{}, len(values))
-	for _, val := range values {
-		valuesMap[val] = struct{}{}
-	}
+	return withIn(values, false)
+}
+
+// WithInFold is WithIn with case insensitive matching, for values like emails and usernames.
+//   - Usable like 'WithIn', ieq=Admin@Example.com matches admin@example.com.
+func WithInFold(values ...string) optionValidateFunc {
+	return withIn(values, true)
+}
+
+func withIn(values []string, fold bool) optionValidateFunc {
+	has := valueSet(values, fold)
+	hasFold := valueSet(values, true)
 
 	return func(key string, v *Validator, t funcType) error {
 		switch t {
 		case sortType:
-			v.sort = append(v.sort, func(q *Query) error {
+			v.sort = append(v.sort, func(ctx context.Context, q *Query) error {
 				for _, cmp := range q.Sort {
-					if _, ok := valuesMap[cmp.Field]; !ok {
+					if !has(cmp.Field) {
 						return fmt.Errorf("value [%s] is not in %v", cmp.Field, values)
 					}
 				}
@@ -289,9 +400,9 @@ func WithIn(values ...string) optionValidateFunc {
 				return nil
 			})
 		case valuesType:
-			v.values = append(v.values, func(q *Query) error {
+			v.values = append(v.values, func(ctx context.Context, q *Query) error {
 				for vKey := range q.Values {
-					if _, ok := valuesMap[vKey]; !ok {
+					if !has(vKey) {
 						return fmt.Errorf("value [%s] is not in %v", vKey, values)
 					}
 				}
@@ -299,44 +410,47 @@ func WithIn(values ...string) optionValidateFunc {
 				return nil
 			})
 		case fieldsType:
-			v.fields = append(v.fields, func(q *Query) error {
-				for _, cmp := range q.Select {
-					if _, ok := valuesMap[cmp]; !ok {
+			v.fields = append(v.fields, func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.selectFields() {
+					if !has(cmp) {
 						return fmt.Errorf("value [%s] is not in %v", cmp, values)
 					}
 				}
 
 				return nil
 			})
-		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
-				for _, cmp := range q.Values[key] {
-					if cmp.Operator == OperatorEq && cmp.Value != nil {
-						cmpStr, ok := cmp.Value.(string)
-						if !ok {
-							return fmt.Errorf("value [%v] is not a string for in", cmp.Value)
-						}
-
-						if _, ok := valuesMap[cmpStr]; !ok {
-							return fmt.Errorf("value [%s] is not in %v", cmpStr, values)
-						}
+		case groupType:
+			v.group = append(v.group, func(ctx context.Context, q *Query) error {
+				for _, field := range q.Group {
+					if !has(field) {
+						return fmt.Errorf("value [%s] is not in %v", field, values)
+					}
+				}
 
-						return nil
+				return nil
+			})
+		case aggregateType:
+			v.aggregate = append(v.aggregate, func(ctx context.Context, q *Query) error {
+				for _, a := range q.Aggregates() {
+					if !has(a.String()) {
+						return fmt.Errorf("value [%s] is not in %v", a, values)
 					}
+				}
 
-					if cmp.Operator == OperatorIn && cmp.Value != nil {
-						cmpIn, ok := cmp.Value.([]string)
-						if !ok {
-							return fmt.Errorf("value [%v] is not a string for in", cmp.Value)
-						}
+				return nil
+			})
+		case valueType:
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.Values[key] {
+					check := has
+					if isFoldOperator(cmp.Operator) {
+						check = hasFold
+					}
 
-						for _, val := range cmpIn {
-							if _, ok := valuesMap[val]; !ok {
-								return fmt.Errorf("value [%s] is not in %v", val, values)
-							}
+					for _, val := range cmpValues(cmp) {
+						if !check(val) {
+							return fmt.Errorf("value [%s] is not in %v", val, values)
 						}
-
-						return nil
 					}
 				}
 
@@ -349,19 +463,30 @@ func WithIn(values ...string) optionValidateFunc {
 }
 
 // WithNotIn checks if the value is not in the list of values.
-//   - Usable for 'WithValue', 'WithSort', 'WithValues', 'WithFields'
+//   - Usable for 'WithValue', 'WithSort', 'WithValues', 'WithFields', 'WithGroup', 'WithAggregate'
+//   - For 'WithValue' every comparison of the key is checked, whatever the operator.
+//     Values of the ieq, ine and iin operators are matched case insensitive, role[ieq]=ADMIN is rejected like admin.
+//   - For 'WithFields' the argument of an aggregate is checked, e.g. amount for sum(amount).
 func WithNotIn(values ...string) optionValidateFunc {
-	valuesMap := make(map[string]struct{}, len(values))
-	for _, val := range values {
-		valuesMap[val] = struct{}{}
-	}
+	return withNotIn(values, false)
+}
+
+// WithNotInFold is WithNotIn with case insensitive matching, Admin is rejected like admin.
+//   - Usable like 'WithNotIn'.
+func WithNotInFold(values ...string) optionValidateFunc {
+	return withNotIn(values, true)
+}
+
+func withNotIn(values []string, fold bool) optionValidateFunc {
+	has := valueSet(values, fold)
+	hasFold := valueSet(values, true)
 
 	return func(key string, v *Validator, t funcType) error {
 		switch t {
 		case sortType:
-			v.sort = append(v.sort, func(q *Query) error {
+			v.sort = append(v.sort, func(ctx context.Context, q *Query) error {
 				for _, cmp := range q.Sort {
-					if _, ok := valuesMap[cmp.Field]; ok {
+					if has(cmp.Field) {
 						return fmt.Errorf("value [%s] is in %v", cmp.Field, values)
 					}
 				}
@@ -369,9 +494,9 @@ func WithNotIn(values ...string) optionValidateFunc {
 				return nil
 			})
 		case valuesType:
-			v.values = append(v.values, func(q *Query) error {
+			v.values = append(v.values, func(ctx context.Context, q *Query) error {
 				for vKey := range q.Values {
-					if _, ok := valuesMap[vKey]; ok {
+					if has(vKey) {
 						return fmt.Errorf("value [%s] is in %v", vKey, values)
 					}
 				}
@@ -379,44 +504,47 @@ func WithNotIn(values ...string) optionValidateFunc {
 				return nil
 			})
 		case fieldsType:
-			v.fields = append(v.fields, func(q *Query) error {
-				for _, cmp := range q.Select {
-					if _, ok := valuesMap[cmp]; ok {
+			v.fields = append(v.fields, func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.selectFields() {
+					if has(cmp) {
 						return fmt.Errorf("value [%s] is in %v", cmp, values)
 					}
 				}
 
 				return nil
 			})
-		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
-				for _, cmp := range q.Values[key] {
-					if cmp.Operator == OperatorEq && cmp.Value != nil {
-						cmpStr, ok := cmp.Value.(string)
-						if !ok {
-							return fmt.Errorf("value [%v] is not a string for in", cmp.Value)
-						}
-
-						if _, ok := valuesMap[cmpStr]; ok {
-							return fmt.Errorf("value [%s] is in %v", cmpStr, values)
-						}
+		case groupType:
+			v.group = append(v.group, func(ctx context.Context, q *Query) error {
+				for _, field := range q.Group {
+					if has(field) {
+						return fmt.Errorf("value [%s] is in %v", field, values)
+					}
+				}
 
-						return nil
+				return nil
+			})
+		case aggregateType:
+			v.aggregate = append(v.aggregate, func(ctx context.Context, q *Query) error {
+				for _, a := range q.Aggregates() {
+					if has(a.String()) {
+						return fmt.Errorf("value [%s] is in %v", a, values)
 					}
+				}
 
-					if cmp.Operator == OperatorIn && cmp.Value != nil {
-						cmpIn, ok := cmp.Value.([]string)
-						if !ok {
-							return fmt.Errorf("value [%v] is not a string for in", cmp.Value)
-						}
+				return nil
+			})
+		case valueType:
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.Values[key] {
+					check := has
+					if isFoldOperator(cmp.Operator) {
+						check = hasFold
+					}
 
-						for _, val := range cmpIn {
-							if _, ok := valuesMap[val]; ok {
-								return fmt.Errorf("value [%s] is in %v", val, values)
-							}
+					for _, val := range cmpValues(cmp) {
+						if check(val) {
+							return fmt.Errorf("value [%s] is in %v", val, values)
 						}
-
-						return nil
 					}
 				}
 
@@ -428,13 +556,40 @@ func WithNotIn(values ...string) optionValidateFunc {
 	}
 }
 
+// isFoldOperator reports whether the operator compares case insensitive.
+func isFoldOperator(op operatorCmpType) bool {
+	return op == OperatorIEq || op == OperatorINe || op == OperatorIIn
+}
+
+// valueSet returns a lookup of the values, case insensitive when fold is true.
+func valueSet(values []string, fold bool) func(string) bool {
+	valuesMap := make(map[string]struct{}, len(values))
+	for _, val := range values {
+		if fold {
+			val = strings.ToLower(val)
+		}
+
+		valuesMap[val] = struct{}{}
+	}
+
+	return func(val string) bool {
+		if fold {
+			val = strings.ToLower(val)
+		}
+
+		_, ok := valuesMap[val]
+
+		return ok
+	}
+}
+
 // WithNotEmpty to validate the value is not empty.
 //   - Usable for 'WithValue'
 func WithNotEmpty() optionValidateFunc {
 	return func(key string, v *Validator, t funcType) error {
 		switch t {
 		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
 				values := q.GetValues(key)
 				if len(values) == 0 {
 					return fmt.Errorf("value [%s] is empty", key)
@@ -457,7 +612,7 @@ func WithRequired() optionValidateFunc {
 	return func(key string, v *Validator, t funcType) error {
 		switch t {
 		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
 				values := q.GetValues(key)
 				if len(values) > 0 {
 					return nil
@@ -472,12 +627,12 @@ func WithRequired() optionValidateFunc {
 }
 
 // WithNotAllowed to validate the value is not allowed.
-//   - Usable for 'WithValue', 'WithOffset', 'WithLimit', 'WithSort', 'WithValues', 'WithFields'
+//   - Usable for 'WithValue', 'WithOffset', 'WithLimit', 'WithSort', 'WithValues', 'WithFields', 'WithGroup', 'WithAggregate'
 func WithNotAllowed() optionValidateFunc {
 	return func(key string, v *Validator, t funcType) error {
 		switch t {
 		case offsetType:
-			v.offset = append(v.offset, func(q *Query) error {
+			v.offset = append(v.offset, func(ctx context.Context, q *Query) error {
 				if q.Offset != nil {
 					return fmt.Errorf("offset is not allowed")
 				}
@@ -485,7 +640,7 @@ func WithNotAllowed() optionValidateFunc {
 				return nil
 			})
 		case limitType:
-			v.limit = append(v.limit, func(q *Query) error {
+			v.limit = append(v.limit, func(ctx context.Context, q *Query) error {
 				if q.Limit != nil {
 					return fmt.Errorf("limit is not allowed")
 				}
@@ -493,7 +648,7 @@ func WithNotAllowed() optionValidateFunc {
 				return nil
 			})
 		case sortType:
-			v.sort = append(v.sort, func(q *Query) error {
+			v.sort = append(v.sort, func(ctx context.Context, q *Query) error {
 				if len(q.Sort) > 0 {
 					return fmt.Errorf("sort is not allowed")
 				}
@@ -501,7 +656,7 @@ func WithNotAllowed() optionValidateFunc {
 				return nil
 			})
 		case valuesType:
-			v.values = append(v.values, func(q *Query) error {
+			v.values = append(v.values, func(ctx context.Context, q *Query) error {
 				if len(q.Values) > 0 {
 					return fmt.Errorf("values is not allowed")
 				}
@@ -509,15 +664,31 @@ func WithNotAllowed() optionValidateFunc {
 				return nil
 			})
 		case fieldsType:
-			v.fields = append(v.fields, func(q *Query) error {
+			v.fields = append(v.fields, func(ctx context.Context, q *Query) error {
 				if len(q.Select) > 0 {
 					return fmt.Errorf("fields is not allowed")
 				}
 
+				return nil
+			})
+		case groupType:
+			v.group = append(v.group, func(ctx context.Context, q *Query) error {
+				if len(q.Group) > 0 {
+					return fmt.Errorf("group is not allowed")
+				}
+
+				return nil
+			})
+		case aggregateType:
+			v.aggregate = append(v.aggregate, func(ctx context.Context, q *Query) error {
+				if len(q.Aggregates()) > 0 {
+					return fmt.Errorf("aggregate is not allowed")
+				}
+
 				return nil
 			})
 		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
 				if len(q.Values[key]) > 0 {
 					return fmt.Errorf("value [%s] is not allowed", key)
 				}
@@ -541,7 +712,7 @@ func WithOperator(operators ...operatorCmpType) optionValidateFunc {
 	return func(key string, v *Validator, t funcType) error {
 		switch t {
 		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
 				for _, cmp := range q.Values[key] {
 					if _, ok := operatorsMap[cmp.Operator]; !ok {
 						return fmt.Errorf("operator [%s] is not allowed", cmp.Operator)
@@ -567,7 +738,7 @@ func WithNotOperator(operators ...operatorCmpType) optionValidateFunc {
 	return func(key string, v *Validator, t funcType) error {
 		switch t {
 		case valueType:
-			v.value[key] = append(v.value[key], func(q *Query) error {
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
 				for _, cmp := range q.Values[key] {
 					if _, ok := operatorsMap[cmp.Operator]; ok {
 						return fmt.Errorf("operator [%s] is not allowed", cmp.Operator)
@@ -582,51 +753,226 @@ func WithNotOperator(operators ...operatorCmpType) optionValidateFunc {
 	}
 }
 
+// WithPattern to validate the value matches the regular expression.
+//   - Usable for 'WithValue'
+func WithPattern(pattern *regexp.Regexp) optionValidateFunc {
+	return func(key string, v *Validator, t funcType) error {
+		if pattern == nil {
+			return fmt.Errorf("pattern is nil")
+		}
+
+		switch t {
+		case valueType:
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.Values[key] {
+					for _, val := range cmpValues(cmp) {
+						if !pattern.MatchString(val) {
+							return fmt.Errorf("value [%s] does not match pattern [%s]", val, pattern)
+						}
+					}
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	}
+}
+
+// WithLength to validate the length of the value in characters.
+//   - Usable for 'WithValue'
+//   - max <= 0 means no maximum.
+func WithLength(min, max int) optionValidateFunc {
+	return func(key string, v *Validator, t funcType) error {
+		if max > 0 && min > max {
+			return fmt.Errorf("length min [%d] is greater than max [%d]", min, max)
+		}
+
+		switch t {
+		case valueType:
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.Values[key] {
+					for _, val := range cmpValues(cmp) {
+						l := utf8.RuneCountInString(val)
+						if l < min {
+							return fmt.Errorf("value [%s] length [%d] is less than min [%d]", val, l, min)
+						}
+
+						if max > 0 && l > max {
+							return fmt.Errorf("value [%s] length [%d] is greater than max [%d]", val, l, max)
+						}
+					}
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	}
+}
+
+// WithMaxItems to validate the number of items in a list value like 'in', 'nin', 'jin', 'njin' and array operators.
+//   - Usable for 'WithValue'
+//   - 0 or less means no limit, like WithMaxListItems.
+func WithMaxItems(n int) optionValidateFunc {
+	return func(key string, v *Validator, t funcType) error {
+		if n <= 0 {
+			return nil
+		}
+
+		switch t {
+		case valueType:
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.Values[key] {
+					if l := len(cmpValues(cmp)); l > n {
+						return fmt.Errorf("value [%s] has [%d] items, more than max [%d]", key, l, n)
+					}
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	}
+}
+
+// WithCustom adds a custom rule called for every comparison of the key.
+// It is usable for 'WithValue' and ctx is the context given to ValidateContext.
+//
+//	query.WithValue("tenant_id", query.WithCustom(func(ctx context.Context, cmp *query.ExpressionCmp) error {
+//	    if cmp.Operator != query.OperatorEq || cmp.Value != tenantFromContext(ctx) {
+//	        return errors.New("tenant not allowed")
+//	    }
+//	    return nil
+//	}))
+func WithCustom(fn func(ctx context.Context, cmp *ExpressionCmp) error) optionValidateFunc {
+	return func(key string, v *Validator, t funcType) error {
+		if fn == nil {
+			return fmt.Errorf("custom function is nil")
+		}
+
+		switch t {
+		case valueType:
+			v.value[key] = append(v.value[key], func(ctx context.Context, q *Query) error {
+				for _, cmp := range q.Values[key] {
+					if err := fn(ctx, cmp); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	}
+}
+
+// cmpValues returns every value of the comparison as a string.
+//   - List values (in, nin, jin, njin and comma split) return one entry per item.
+//   - Typed values are formatted with their default format.
+//   - nil values (is, not) return nil.
+func cmpValues(cmp *ExpressionCmp) []string {
+	switch v := cmp.Value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	}
+
+	rv := reflect.ValueOf(cmp.Value)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		result := make([]string, 0, rv.Len())
+		for i := range rv.Len() {
+			result = append(result, fmt.Sprint(rv.Index(i).Interface()))
+		}
+
+		return result
+	}
+
+	return []string{fmt.Sprint(cmp.Value)}
+}
+
 // ///////////////////////////////////////////////////////////////////
 // ///////////////////////////////////////////////////////////////////
 
+// Validate validates the query with the validator.
 func (q *Query) Validate(v *Validator) error {
+	return q.ValidateContext(context.Background(), v)
+}
+
+// ValidateContext validates the query with the validator, passing ctx to the custom rules.
+func (q *Query) ValidateContext(ctx context.Context, v *Validator) error {
 	if v == nil {
 		return nil
 	}
 
 	for key, f := range v.value {
 		for _, fn := range f {
-			if err := fn(q); err != nil {
+			if err := fn(ctx, q); err != nil {
 				return fmt.Errorf("validate [%s]: %w", key, err)
 			}
 		}
 	}
 
 	for _, fn := range v.fields {
-		if err := fn(q); err != nil {
+		if err := fn(ctx, q); err != nil {
 			return fmt.Errorf("validate fields: %w", err)
 		}
 	}
 
 	for _, fn := range v.values {
-		if err := fn(q); err != nil {
+		if err := fn(ctx, q); err != nil {
 			return fmt.Errorf("validate values: %w", err)
 		}
 	}
 
 	for _, fn := range v.offset {
-		if err := fn(q); err != nil {
+		if err := fn(ctx, q); err != nil {
 			return fmt.Errorf("validate offset: %w", err)
 		}
 	}
 
 	for _, fn := range v.limit {
-		if err := fn(q); err != nil {
+		if err := fn(ctx, q); err != nil {
 			return fmt.Errorf("validate limit: %w", err)
 		}
 	}
 
 	for _, fn := range v.sort {
-		if err := fn(q); err != nil {
+		if err := fn(ctx, q); err != nil {
 			return fmt.Errorf("validate sort: %w", err)
 		}
 	}
 
+	for _, fn := range v.group {
+		if err := fn(ctx, q); err != nil {
+			return fmt.Errorf("validate group: %w", err)
+		}
+	}
+
+	for _, fn := range v.aggregate {
+		if err := fn(ctx, q); err != nil {
+			return fmt.Errorf("validate aggregate: %w", err)
+		}
+	}
+
+	for _, fn := range v.where {
+		if err := fn(ctx, q); err != nil {
+			return fmt.Errorf("validate where: %w", err)
+		}
+	}
+
+	for _, fn := range v.query {
+		if err := fn(ctx, q); err != nil {
+			return fmt.Errorf("validate query: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -1,7 +1,10 @@
 package query
 
 import (
+	"context"
+	"fmt"
 	"net/url"
+	"regexp"
 	"testing"
 )
 
@@ -295,6 +298,152 @@ func TestQuery_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "in every operator",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?member[ne]=Z",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?member[like]=Z",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?member=X&member=Z",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?member=X|member[ne]=Y",
+					wantErr: false,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("member", WithIn("X", "Y")),
+				},
+			},
+		},
+		{
+			name: "not in every operator",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?member=X&member[nin]=A,O",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?member[gt]=X",
+					wantErr: false,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("member", WithNotIn("O", "P")),
+				},
+			},
+		},
+		{
+			name: "min max every operator",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?age[gt]=500",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?age=10&age[lt]=-1",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?age[gte]=10&age[lte]=20",
+					wantErr: false,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("age", WithMin("0"), WithMax("200")),
+				},
+			},
+		},
+		{
+			name: "pattern",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?code=AB12",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?code=AB12,ab12",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?code[ne]=x",
+					wantErr: true,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("code", WithPattern(regexp.MustCompile(`^[A-Z]{2}[0-9]{2}$`))),
+				},
+			},
+		},
+		{
+			name: "length",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?name=foo",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?name=fo",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?name[ilike]=foobarbaz",
+					wantErr: true,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("name", WithLength(3, 5)),
+				},
+			},
+		},
+		{
+			name: "max items",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?id=1,2",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?id[nin]=1,2,3",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?id[jin]=1,2,3",
+					wantErr: true,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("id", WithMaxItems(2)),
+				},
+			},
+		},
+		{
+			name: "max items zero is no limit",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?id=1,2,3,4",
+					wantErr: false,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("id", WithMaxItems(0)),
+				},
+			},
+		},
 		{
 			name: "sort not in",
 			cases: []subCase{
@@ -313,6 +462,107 @@ func TestQuery_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "case insensitive allowlist",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?role[ieq]=Admin",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?role[iin]=EDITOR,admin",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?role[ieq]=root",
+					wantErr: true,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("role", WithInFold("admin", "editor")),
+				},
+			},
+		},
+		{
+			name: "case insensitive denylist",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?username[ine]=ROOT",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?username=bob&_sort=-Password",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?username=bob&_sort=name",
+					wantErr: false,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("username", WithNotInFold("root")),
+					WithSort(WithNotInFold("password")),
+				},
+			},
+		},
+		{
+			name: "denylist with case insensitive operators",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?role[ieq]=ADMIN",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?role[iin]=editor,ADMIN",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?role[ine]=Admin",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?role=ADMIN",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?role[ieq]=Editor",
+					wantErr: false,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("role", WithNotIn("admin")),
+				},
+			},
+		},
+		{
+			name: "allowlist with case insensitive operators",
+			cases: []subCase{
+				{
+					URL:     "http://example.com?role[ine]=ADMIN",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?role[iin]=Editor,admin",
+					wantErr: false,
+				},
+				{
+					URL:     "http://example.com?role=ADMIN",
+					wantErr: true,
+				},
+				{
+					URL:     "http://example.com?role[ieq]=root",
+					wantErr: true,
+				},
+			},
+			args: args{
+				opts: []OptionValidateSet{
+					WithValue("role", WithIn("admin", "editor")),
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -341,3 +591,80 @@ func TestQuery_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestQuery_ValidateTypedValue(t *testing.T) {
+	validate, err := NewValidator(
+		WithValue("age", WithMin("0"), WithMax("200")),
+		WithValue("active", WithIn("true")),
+	)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	q := New().AddWhere(NewExpressionCmp(OperatorGt, "age", 500))
+	if err := q.Validate(validate); err == nil {
+		t.Errorf("Query.Validate() expected error for typed value")
+	}
+
+	q = New().AddWhere(NewExpressionCmp(OperatorIn, "age", []int{10, 20}), NewExpressionCmp(OperatorEq, "active", true))
+	if err := q.Validate(validate); err != nil {
+		t.Errorf("Query.Validate() error = %v", err)
+	}
+
+	q = New().AddWhere(NewExpressionCmp(OperatorIn, "active", []bool{true, false}))
+	if err := q.Validate(validate); err == nil {
+		t.Errorf("Query.Validate() expected error for typed list value")
+	}
+}
+
+func TestQuery_ValidateContextCustom(t *testing.T) {
+	type tenantKey struct{}
+
+	validate, err := NewValidator(
+		WithValue("tenant_id", WithCustom(func(ctx context.Context, cmp *ExpressionCmp) error {
+			if cmp.Operator != OperatorEq {
+				return fmt.Errorf("operator [%s] is not allowed", cmp.Operator)
+			}
+
+			if cmp.Value != ctx.Value(tenantKey{}) {
+				return fmt.Errorf("tenant [%v] is not allowed", cmp.Value)
+			}
+
+			return nil
+		})),
+		WithQueryCustom(func(ctx context.Context, q *Query) error {
+			if !q.Has("tenant_id") {
+				return fmt.Errorf("tenant_id is required")
+			}
+
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "1")
+
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{query: "tenant_id=1", wantErr: false},
+		{query: "tenant_id=2", wantErr: true},
+		{query: "tenant_id=1|tenant_id=2", wantErr: true},
+		{query: "tenant_id[ne]=1", wantErr: true},
+		{query: "name=foo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("failed to parse query: %v", err)
+		}
+
+		if err := q.ValidateContext(ctx, validate); (err != nil) != tt.wantErr {
+			t.Errorf("Query.ValidateContext(%s) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+	}
+}